@@ -3,53 +3,115 @@ package flagx
 import (
 	"flag"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 // NewArrayString returns new ArrayString with the given name and description.
 func NewArrayString(name, description string) *ArrayString {
-	description += "\nSupports an `array` of values separated by comma or specified via multiple flags." + envHelp(name)
+	return CommandLine.NewArrayString(name, description)
+}
+
+// NewArrayString returns new ArrayString with the given name and description.
+func (s *Set) NewArrayString(name, description string) *ArrayString {
+	description += "\nSupports an `array` of values separated by comma or specified via multiple flags."
 	var a ArrayString
-	flag.Var(&a, name, description)
+	s.fs.Var(&a, name, description)
 	return &a
 }
 
-// NewArrayDuration returns new ArrayDuration with the given name and description.
-func NewArrayDuration(name, description string) *ArrayDuration {
-	description += "\nSupports `array` of values separated by comma or specified via multiple flags." + envHelp(name)
+// NewArrayDuration returns new ArrayDuration with the given name, description and optional opts.
+func NewArrayDuration(name, description string, opts ...Option) *ArrayDuration {
+	return CommandLine.NewArrayDuration(name, description, opts...)
+}
+
+// NewArrayDuration returns new ArrayDuration with the given name, description and optional opts.
+//
+// Values are parsed the same way as Duration flag values.
+// AllowNegative, MinDuration, MaxDuration and Validate[time.Duration] options are supported.
+// The validation options are applied to every value.
+func (s *Set) NewArrayDuration(name, description string, opts ...Option) *ArrayDuration {
+	o := applyOptions(opts)
+	validators, help := o.durationChecks(name)
+	description += durationHelp + arrayHelp + help
 	var a ArrayDuration
-	flag.Var(&a, name, description)
+	s.fs.Var(&arrayDurationValue{
+		a:             &a,
+		allowNegative: o.allowNegative,
+		validators:    validators,
+	}, name, description)
 	return &a
 }
 
 // NewArrayBool returns new ArrayBool with the given name and description.
 func NewArrayBool(name, description string) *ArrayBool {
-	description += "\nSupports `array` of values separated by comma or specified via multiple flags." + envHelp(name)
+	return CommandLine.NewArrayBool(name, description)
+}
+
+// NewArrayBool returns new ArrayBool with the given name and description.
+func (s *Set) NewArrayBool(name, description string) *ArrayBool {
+	description += arrayHelp
 	var a ArrayBool
-	flag.Var(&a, name, description)
+	s.fs.Var(&a, name, description)
 	return &a
 }
 
 // NewArrayInt returns new ArrayInt with the given name and description.
 func NewArrayInt(name, description string) *ArrayInt {
-	description += "\nSupports `array` of values separated by comma or specified via multiple flags." + envHelp(name)
+	return CommandLine.NewArrayInt(name, description)
+}
+
+// NewArrayInt returns new ArrayInt with the given name and description.
+func (s *Set) NewArrayInt(name, description string) *ArrayInt {
+	description += arrayHelp
 	var a ArrayInt
-	flag.Var(&a, name, description)
+	s.fs.Var(&a, name, description)
 	return &a
 }
 
 // NewArrayBytes returns new ArrayBytes with the given name and description.
 func NewArrayBytes(name, description string) *ArrayBytes {
-	description += "\nSupports the following optional suffixes for size values: KB, MB, GB, TB, KiB, MiB, GiB, TiB."
-	description += "\nSupports `array` of values separated by comma or specified via multiple flags." + envHelp(name)
+	return CommandLine.NewArrayBytes(name, description)
+}
+
+// NewArrayBytes returns new ArrayBytes with the given name and description.
+func (s *Set) NewArrayBytes(name, description string) *ArrayBytes {
+	description += arrayBytesHelp
+	description += arrayHelp
 	var a ArrayBytes
-	flag.Var(&a, name, description)
+	s.fs.Var(&a, name, description)
 	return &a
 }
 
-// ArrayString is a flag that holds an array of strings.
+const (
+	arrayHelp      = "\nSupports `array` of values separated by comma or specified via multiple flags."
+	arrayBytesHelp = "\nSupports the following optional suffixes for size values: KB, MB, GB, TB, KiB, MiB, GiB, TiB."
+)
+
+// NewArray returns new Array[T] with the given name and description.
+//
+// See Array for the supported element types.
+func NewArray[T any](name, description string) *Array[T] {
+	return NewSetArray[T](CommandLine, name, description)
+}
+
+// NewSetArray returns new Array[T] with the given name and description registered at s.
+//
+// See Array for the supported element types.
+func NewSetArray[T any](s *Set, name, description string) *Array[T] {
+	if _, err := getArrayCodec[T](); err != nil {
+		panic(fmt.Sprintf("BUG: cannot create flag %s: %s", name, err))
+	}
+	description += arrayHelp
+	var a Array[T]
+	s.fs.Var(&a, name, description)
+	return &a
+}
+
+// Array is a flag that holds an array of values of type T.
 //
 // It may be set either by specifying multiple flags with the given name
 // passed to NewArray or by joining flag values by comma.
@@ -65,40 +127,371 @@ func NewArrayBytes(name, description string) *ArrayBytes {
 // Flag values may be quoted. For instance, the following arg creates an array of ("a", "b,c") items:
 //
 //	-foo='a,"b,c"'
-type ArrayString []string
+//
+// Items are parsed and formatted by functions registered via RegisterArrayType.
+// string, bool, signed and unsigned integers, floats, time.Duration, *Bytes, net.IP and *url.URL
+// are supported out of the box, as well as types implementing encoding.TextUnmarshaler.
+//
+// Array flags may be updated by Reload, so use Snapshot for reading them
+// from goroutines running concurrently with Reload.
+type Array[T any] []T
+
+// ArrayString is a flag that holds an array of strings.
+//
+// Has the same api as Array.
+type ArrayString = Array[string]
+
+// ArrayBool is a flag that holds an array of booleans values.
+//
+// Has the same api as Array.
+type ArrayBool = Array[bool]
+
+// ArrayInt is flag that holds an array of ints.
+//
+// Has the same api as Array.
+type ArrayInt = Array[int]
+
+// arrayLocks protect Array values from concurrent access by Set and Snapshot.
+//
+// Array types are slices, so they cannot hold their own lock. Every value is protected by the lock
+// selected by its address, so updates of unrelated values rarely contend for the same lock.
+var arrayLocks [256]sync.RWMutex
+
+// lock returns the lock protecting a.
+func (a *Array[T]) lock() *sync.RWMutex {
+	h := uint64(reflect.ValueOf(a).Pointer()) * 0x9e3779b97f4a7c15
+	return &arrayLocks[h>>56]
+}
+
+// Snapshot returns a copy of a.
+//
+// It is safe calling Snapshot concurrently with Set and Reload.
+func (a *Array[T]) Snapshot() []T {
+	return append([]T(nil), a.load()...)
+}
+
+// load returns the stored values. The returned slice mustn't be modified.
+//
+// The stored values are never modified in place, so the returned slice remains valid after the next Set call.
+func (a *Array[T]) load() []T {
+	mu := a.lock()
+	mu.RLock()
+	x := *a
+	mu.RUnlock()
+	return x
+}
+
+// store replaces the stored values with values, which mustn't be modified after the call.
+func (a *Array[T]) store(values []T) {
+	mu := a.lock()
+	mu.Lock()
+	*a = values
+	mu.Unlock()
+}
+
+// add appends values to the stored values.
+func (a *Array[T]) add(values []T) {
+	if len(values) == 0 {
+		return
+	}
+	mu := a.lock()
+	mu.Lock()
+	defer mu.Unlock()
+	x := make([]T, 0, len(*a)+len(values))
+	x = append(x, *a...)
+	*a = append(x, values...)
+}
+
+// IsBoolFlag implements flag.IsBoolFlag interface
+//
+// It returns true for Array[bool], so it may be set via `-flag` without a value.
+func (a *Array[T]) IsBoolFlag() bool {
+	_, ok := any(a).(*Array[bool])
+	return ok
+}
+
+// itemTypeName returns the name of T.
+func (a *Array[T]) itemTypeName() string {
+	return reflect.TypeFor[T]().String()
+}
 
 // String implements flag.Value interface
-func (a *ArrayString) String() string {
-	aEscaped := make([]string, len(*a))
-	for i, v := range *a {
-		if strings.ContainsAny(v, `,'"{[(`+"\n") {
-			v = fmt.Sprintf("%q", v)
+func (a *Array[T]) String() string {
+	x := a.load()
+	format := func(v T) string {
+		return fmt.Sprint(v)
+	}
+	if ac, err := getArrayCodec[T](); err == nil {
+		format = ac.format
+	}
+	formatted := make([]string, len(x))
+	for i, v := range x {
+		formatted[i] = format(v)
+	}
+	return strings.Join(formatted, ",")
+}
+
+func (a *Array[T]) replace(value string) error {
+	values, err := a.parse(value)
+	if err != nil {
+		return err
+	}
+	a.store(values)
+	return nil
+}
+
+// Set implements flag.Value interface
+func (a *Array[T]) Set(value string) error {
+	values, err := a.parse(value)
+	if err != nil {
+		return err
+	}
+	a.add(values)
+	return nil
+}
+
+func (a *Array[T]) parse(value string) ([]T, error) {
+	ac, err := getArrayCodec[T]()
+	if err != nil {
+		return nil, err
+	}
+	items := parseArrayValues(value)
+	values := make([]T, 0, len(items))
+	for _, item := range items {
+		v, err := ac.parse(item)
+		if err != nil {
+			return nil, err
 		}
-		aEscaped[i] = v
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// GetOptionalArg returns optional arg under the given argIdx.
+//
+// The only item is returned for any argIdx if a contains a single item.
+// Zero value is returned if argIdx isn't found.
+func (a *Array[T]) GetOptionalArg(argIdx int) T {
+	var zero T
+	return a.GetOptionalArgOrDefault(argIdx, zero)
+}
+
+// GetOptionalArgOrDefault returns optional arg under the given argIdx,
+// or default value, if argIdx not found.
+//
+// The only item is returned for any argIdx if a contains a single item.
+func (a *Array[T]) GetOptionalArgOrDefault(argIdx int, defaultValue T) T {
+	x := a.load()
+	if argIdx < len(x) {
+		return x[argIdx]
+	}
+	if len(x) == 1 {
+		return x[0]
 	}
-	return strings.Join(aEscaped, ",")
+	return defaultValue
+}
+
+// ArrayBytes is flag that holds an array of Bytes.
+//
+// Has the same api as Array, except of GetOptionalArgOrDefault, which works with int64 values.
+type ArrayBytes Array[*Bytes]
+
+// Snapshot returns a copy of a.
+//
+// It is safe calling Snapshot concurrently with Set and Reload.
+func (a *ArrayBytes) Snapshot() []*Bytes {
+	return (*Array[*Bytes])(a).Snapshot()
+}
+
+// String implements flag.Value interface
+func (a *ArrayBytes) String() string {
+	return (*Array[*Bytes])(a).String()
+}
+
+func (a *ArrayBytes) replace(value string) error {
+	return (*Array[*Bytes])(a).replace(value)
+}
+
+// Set implemented flag.Value interface
+func (a *ArrayBytes) Set(value string) error {
+	return (*Array[*Bytes])(a).Set(value)
+}
+
+// GetOptionalArgOrDefault returns optional arg under the given argIdx.
+func (a *ArrayBytes) GetOptionalArgOrDefault(argIdx int, defaultValue int64) int64 {
+	x := (*Array[*Bytes])(a).load()
+	if argIdx < len(x) {
+		return x[argIdx].Load()
+	}
+	if len(x) == 1 {
+		return x[0].Load()
+	}
+	return defaultValue
+}
+
+// ArrayDuration is a flag that holds an array of time.Duration values.
+//
+// Has the same api as Array. Values are parsed the same way as Duration flag values,
+// i.e. `-retention=30d,1y` or `-retention=2h5m,90`.
+//
+// Flags created via NewArrayDuration apply the given options to every value
+// and print the values in the form they were set.
+type ArrayDuration Array[time.Duration]
+
+// Snapshot returns a copy of a.
+//
+// It is safe calling Snapshot concurrently with Set and Reload.
+func (a *ArrayDuration) Snapshot() []time.Duration {
+	return (*Array[time.Duration])(a).Snapshot()
+}
+
+// String implements flag.Value interface
+func (a *ArrayDuration) String() string {
+	return (*Array[time.Duration])(a).String()
 }
 
 // Set implements flag.Value interface
-func (a *ArrayString) Set(value string) error {
-	values := parseArrayValues(value)
-	*a = append(*a, values...)
+func (a *ArrayDuration) Set(value string) error {
+	values, _, err := parseArrayDurations(value, false, nil)
+	if err != nil {
+		return err
+	}
+	(*Array[time.Duration])(a).add(values)
 	return nil
 }
 
+func (a *ArrayDuration) replace(value string) error {
+	values, _, err := parseArrayDurations(value, false, nil)
+	if err != nil {
+		return err
+	}
+	(*Array[time.Duration])(a).store(values)
+	return nil
+}
+
+// GetOptionalArg returns optional arg under the given argIdx.
+//
+// The only item is returned for any argIdx if a contains a single item.
+// Zero value is returned if argIdx isn't found.
+func (a *ArrayDuration) GetOptionalArg(argIdx int) time.Duration {
+	return (*Array[time.Duration])(a).GetOptionalArg(argIdx)
+}
+
+// GetOptionalArgOrDefault returns optional arg under the given argIdx,
+// or default value, if argIdx not found.
+//
+// The only item is returned for any argIdx if a contains a single item.
+func (a *ArrayDuration) GetOptionalArgOrDefault(argIdx int, defaultValue time.Duration) time.Duration {
+	return (*Array[time.Duration])(a).GetOptionalArgOrDefault(argIdx, defaultValue)
+}
+
+// parseArrayDurations parses value into durations and returns them together with the inputs they were parsed from.
+func parseArrayDurations(value string, allowNegative bool, validators []func(d time.Duration) error) ([]time.Duration, []string, error) {
+	inputs := parseArrayValues(value)
+	durations := make([]time.Duration, 0, len(inputs))
+	for _, input := range inputs {
+		d, err := parseDuration(input, allowNegative)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, v := range validators {
+			if err := v(d); err != nil {
+				return nil, nil, fmt.Errorf("invalid value %q: %w", input, err)
+			}
+		}
+		durations = append(durations, d)
+	}
+	return durations, inputs, nil
+}
+
+// arrayDurationValue is a flag.Value for ArrayDuration flags registered by NewArrayDuration and Bind.
+//
+// It holds the options for parsing the values together with the values in the form they were set.
+type arrayDurationValue struct {
+	a *ArrayDuration
+
+	allowNegative bool
+	validators    []func(d time.Duration) error
+
+	// inputs contains the values in the form they were set. It is protected by the lock of a.
+	inputs []string
+}
+
+// flagValue returns the flag value holding the values.
+func (v *arrayDurationValue) flagValue() flag.Value {
+	return v.a
+}
+
+// String implements flag.Value interface
+func (v *arrayDurationValue) String() string {
+	if v.a == nil {
+		// flag.isZeroValue calls String on zero value.
+		return ""
+	}
+	a := (*Array[time.Duration])(v.a)
+	mu := a.lock()
+	mu.RLock()
+	if len(v.inputs) == len(*a) {
+		s := strings.Join(v.inputs, ",")
+		mu.RUnlock()
+		return s
+	}
+	mu.RUnlock()
+	// The values were set directly via a.
+	return a.String()
+}
+
+// Set implements flag.Value interface
+func (v *arrayDurationValue) Set(value string) error {
+	return v.set(value, false)
+}
+
+func (v *arrayDurationValue) replace(value string) error {
+	return v.set(value, true)
+}
+
+func (v *arrayDurationValue) set(value string, reset bool) error {
+	durations, inputs, err := parseArrayDurations(value, v.allowNegative, v.validators)
+	if err != nil {
+		return err
+	}
+	a := (*Array[time.Duration])(v.a)
+	mu := a.lock()
+	mu.Lock()
+	defer mu.Unlock()
+	if !reset {
+		durations = append(append([]time.Duration(nil), *a...), durations...)
+		inputs = append(append([]string(nil), v.inputs...), inputs...)
+	}
+	*a = durations
+	v.inputs = inputs
+	return nil
+}
+
+// formatArrayValues joins items the same way as ArrayString.String does, so they may be parsed back by parseArrayValues.
+func formatArrayValues(items []string) string {
+	a := ArrayString(items)
+	return a.String()
+}
+
 func parseArrayValues(s string) []string {
+	return parseArrayValuesWithSeparator(s, ',')
+}
+
+// parseArrayValuesWithSeparator is like parseArrayValues, but splits s by sep instead of comma.
+func parseArrayValuesWithSeparator(s string, sep byte) []string {
 	if len(s) == 0 {
 		return nil
 	}
 	var values []string
 	for {
-		v, tail := getNextArrayValue(s)
+		v, tail := getNextArrayValue(s, sep)
 		values = append(values, v)
 		if len(tail) == 0 {
 			return values
 		}
 		s = tail
-		if s[0] == ',' {
+		if s[0] == sep {
 			s = s[1:]
 		}
 	}
@@ -112,38 +505,43 @@ var closeQuotes = map[byte]byte{
 	'(':  ')',
 }
 
-func getNextArrayValue(s string) (string, string) {
-	v, tail := getNextArrayValueMaybeQuoted(s)
+func getNextArrayValue(s string, sep byte) (string, string) {
+	v, tail := getNextArrayValueMaybeQuoted(s, sep)
+	return unquoteArrayValue(v), tail
+}
+
+// unquoteArrayValue removes single or double quotes around v if they are present.
+func unquoteArrayValue(v string) string {
 	if strings.HasPrefix(v, `"`) && strings.HasSuffix(v, `"`) {
 		vUnquoted, err := strconv.Unquote(v)
 		if err == nil {
-			return vUnquoted, tail
+			return vUnquoted
 		}
 		v = v[1 : len(v)-1]
 		v = strings.ReplaceAll(v, `\"`, `"`)
 		v = strings.ReplaceAll(v, `\\`, `\`)
-		return v, tail
+		return v
 	}
 	if strings.HasPrefix(v, `'`) && strings.HasSuffix(v, `'`) {
 		v = v[1 : len(v)-1]
 		v = strings.ReplaceAll(v, `\'`, "'")
 		v = strings.ReplaceAll(v, `\\`, `\`)
-		return v, tail
+		return v
 	}
-	return v, tail
+	return v
 }
 
-func getNextArrayValueMaybeQuoted(s string) (string, string) {
+func getNextArrayValueMaybeQuoted(s string, sep byte) (string, string) {
 	idx := 0
 	for {
-		n := strings.IndexAny(s[idx:], `,"'[{(`)
+		n := strings.IndexAny(s[idx:], string(sep)+`"'[{(`)
 		if n < 0 {
 			// The last item
 			return s, ""
 		}
 		idx += n
 		ch := s[idx]
-		if ch == ',' {
+		if ch == sep {
 			// The next item
 			return s[:idx], s[idx:]
 		}
@@ -197,177 +595,3 @@ func getTrailingBackslashesCount(s string) int {
 	}
 	return len(s) - n
 }
-
-// GetOptionalArg returns optional arg under the given argIdx.
-func (a *ArrayString) GetOptionalArg(argIdx int) string {
-	x := *a
-	if argIdx >= len(x) {
-		if len(x) == 1 {
-			return x[0]
-		}
-		return ""
-	}
-	return x[argIdx]
-}
-
-// ArrayBool is a flag that holds an array of booleans values.
-//
-// Has the same api as ArrayString.
-type ArrayBool []bool
-
-// IsBoolFlag  implements flag.IsBoolFlag interface
-func (a *ArrayBool) IsBoolFlag() bool { return true }
-
-// String implements flag.Value interface
-func (a *ArrayBool) String() string {
-	formattedBools := make([]string, len(*a))
-	for i, v := range *a {
-		formattedBools[i] = strconv.FormatBool(v)
-	}
-	return strings.Join(formattedBools, ",")
-}
-
-// Set implements flag.Value interface
-func (a *ArrayBool) Set(value string) error {
-	values := parseArrayValues(value)
-	for _, v := range values {
-		b, err := strconv.ParseBool(v)
-		if err != nil {
-			return err
-		}
-		*a = append(*a, b)
-	}
-	return nil
-}
-
-// GetOptionalArg returns optional arg under the given argIdx.
-func (a *ArrayBool) GetOptionalArg(argIdx int) bool {
-	x := *a
-	if argIdx >= len(x) {
-		if len(x) == 1 {
-			return x[0]
-		}
-		return false
-	}
-	return x[argIdx]
-}
-
-// ArrayDuration is a flag that holds an array of time.Duration values.
-//
-// Has the same api as ArrayString.
-type ArrayDuration []time.Duration
-
-// String implements flag.Value interface
-func (a *ArrayDuration) String() string {
-	formattedBools := make([]string, len(*a))
-	for i, v := range *a {
-		formattedBools[i] = v.String()
-	}
-	return strings.Join(formattedBools, ",")
-}
-
-// Set implements flag.Value interface
-func (a *ArrayDuration) Set(value string) error {
-	values := parseArrayValues(value)
-	for _, v := range values {
-		b, err := time.ParseDuration(v)
-		if err != nil {
-			return err
-		}
-		*a = append(*a, b)
-	}
-	return nil
-}
-
-// GetOptionalArgOrDefault returns optional arg under the given argIdx,
-// or default value, if argIdx not found.
-func (a *ArrayDuration) GetOptionalArgOrDefault(argIdx int, defaultValue time.Duration) time.Duration {
-	x := *a
-	if argIdx >= len(x) {
-		if len(x) == 1 {
-			return x[0]
-		}
-		return defaultValue
-	}
-	return x[argIdx]
-}
-
-// ArrayInt is flag that holds an array of ints.
-//
-// Has the same api as ArrayString.
-type ArrayInt []int
-
-// String implements flag.Value interface
-func (a *ArrayInt) String() string {
-	x := *a
-	formattedInts := make([]string, len(x))
-	for i, v := range x {
-		formattedInts[i] = strconv.Itoa(v)
-	}
-	return strings.Join(formattedInts, ",")
-}
-
-// Set implements flag.Value interface
-func (a *ArrayInt) Set(value string) error {
-	values := parseArrayValues(value)
-	for _, v := range values {
-		n, err := strconv.Atoi(v)
-		if err != nil {
-			return err
-		}
-		*a = append(*a, n)
-	}
-	return nil
-}
-
-// GetOptionalArgOrDefault returns optional arg under the given argIdx.
-func (a *ArrayInt) GetOptionalArgOrDefault(argIdx, defaultValue int) int {
-	x := *a
-	if argIdx < len(x) {
-		return x[argIdx]
-	}
-	if len(x) == 1 {
-		return x[0]
-	}
-	return defaultValue
-}
-
-// ArrayBytes is flag that holds an array of Bytes.
-//
-// Has the same api as ArrayString.
-type ArrayBytes []*Bytes
-
-// String implements flag.Value interface
-func (a *ArrayBytes) String() string {
-	x := *a
-	formattedBytes := make([]string, len(x))
-	for i, v := range x {
-		formattedBytes[i] = v.String()
-	}
-	return strings.Join(formattedBytes, ",")
-}
-
-// Set implemented flag.Value interface
-func (a *ArrayBytes) Set(value string) error {
-	values := parseArrayValues(value)
-	for _, v := range values {
-		var b Bytes
-		if err := b.Set(v); err != nil {
-			return err
-		}
-		*a = append(*a, &b)
-	}
-	return nil
-}
-
-// GetOptionalArgOrDefault returns optional arg under the given argIdx.
-func (a *ArrayBytes) GetOptionalArgOrDefault(argIdx int, defaultValue int64) int64 {
-	x := *a
-	if argIdx < len(x) {
-		return x[argIdx].N
-	}
-	if len(x) == 1 {
-		return x[0].N
-	}
-	return defaultValue
-}
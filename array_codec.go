@@ -0,0 +1,133 @@
+package flagx
+
+import (
+	"encoding"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RegisterArrayType registers parse and format functions for items of Array[T] flags.
+//
+// parse must return an error for invalid items, while format must return a string, which may be parsed back by parse.
+// The registration overrides the previously registered functions for T, including the built-in ones.
+//
+// RegisterArrayType must be called before parsing flags.
+func RegisterArrayType[T any](parse func(s string) (T, error), format func(v T) string) {
+	arrayCodecs.Store(reflect.TypeFor[T](), &arrayCodec[T]{
+		parse:  parse,
+		format: format,
+	})
+}
+
+type arrayCodec[T any] struct {
+	parse  func(s string) (T, error)
+	format func(v T) string
+}
+
+// arrayCodecs contains *arrayCodec[T] values keyed by reflect.Type for T.
+var arrayCodecs sync.Map
+
+// getArrayCodec returns codec for Array[T] items.
+//
+// Types implementing encoding.TextUnmarshaler are supported without registration.
+func getArrayCodec[T any]() (*arrayCodec[T], error) {
+	if ac, ok := arrayCodecs.Load(reflect.TypeFor[T]()); ok {
+		return ac.(*arrayCodec[T]), nil
+	}
+	var zero T
+	if _, ok := any(&zero).(encoding.TextUnmarshaler); ok {
+		return &arrayCodec[T]{
+			parse: func(s string) (T, error) {
+				var v T
+				err := any(&v).(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+				return v, err
+			},
+			format: func(v T) string {
+				if m, ok := any(v).(encoding.TextMarshaler); ok {
+					if data, err := m.MarshalText(); err == nil {
+						return string(data)
+					}
+				}
+				return fmt.Sprint(v)
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported array item type %s; register it via RegisterArrayType", reflect.TypeFor[T]())
+}
+
+func init() {
+	RegisterArrayType(func(s string) (string, error) {
+		return s, nil
+	}, func(v string) string {
+		if strings.ContainsAny(v, `,'"{[(`+"\n") {
+			return fmt.Sprintf("%q", v)
+		}
+		return v
+	})
+	RegisterArrayType(strconv.ParseBool, strconv.FormatBool)
+	RegisterArrayType(strconv.Atoi, strconv.Itoa)
+	registerIntArrayType[int8](8)
+	registerIntArrayType[int16](16)
+	registerIntArrayType[int32](32)
+	registerIntArrayType[int64](64)
+	registerUintArrayType[uint](strconv.IntSize)
+	registerUintArrayType[uint8](8)
+	registerUintArrayType[uint16](16)
+	registerUintArrayType[uint32](32)
+	registerUintArrayType[uint64](64)
+	RegisterArrayType(func(s string) (float32, error) {
+		f, err := strconv.ParseFloat(s, 32)
+		return float32(f), err
+	}, func(v float32) string {
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	})
+	RegisterArrayType(func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	}, func(v float64) string {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	})
+	RegisterArrayType(func(s string) (time.Duration, error) {
+		return parseDuration(s, false)
+	}, time.Duration.String)
+	RegisterArrayType(func(s string) (*Bytes, error) {
+		var b Bytes
+		if err := b.Set(s); err != nil {
+			return nil, err
+		}
+		return &b, nil
+	}, (*Bytes).String)
+	RegisterArrayType(func(s string) (net.IP, error) {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("cannot parse IP address %q", s)
+		}
+		return ip, nil
+	}, net.IP.String)
+	RegisterArrayType(url.Parse, (*url.URL).String)
+	RegisterArrayType(parseCIDR, netip.Prefix.String)
+}
+
+func registerIntArrayType[T int8 | int16 | int32 | int64](bitSize int) {
+	RegisterArrayType(func(s string) (T, error) {
+		n, err := strconv.ParseInt(s, 10, bitSize)
+		return T(n), err
+	}, func(v T) string {
+		return strconv.FormatInt(int64(v), 10)
+	})
+}
+
+func registerUintArrayType[T uint | uint8 | uint16 | uint32 | uint64](bitSize int) {
+	RegisterArrayType(func(s string) (T, error) {
+		n, err := strconv.ParseUint(s, 10, bitSize)
+		return T(n), err
+	}, func(v T) string {
+		return strconv.FormatUint(uint64(v), 10)
+	})
+}
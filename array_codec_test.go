@@ -0,0 +1,111 @@
+package flagx
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestArrayGenericSet(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	floats := NewSetArray[float64](s, "floats", "")
+	ports := NewSetArray[uint16](s, "ports", "")
+	ips := NewSetArray[net.IP](s, "ips", "")
+	addrs := NewSetArray[netip.Addr](s, "addrs", "")
+	if err := s.Parse([]string{"-floats=1.5,-2", "-ports=80,443", "-ports=8080", "-ips=127.0.0.1,::1", "-addrs=10.0.0.1"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result, expected := floats.Snapshot(), []float64{1.5, -2}; !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected floats; got %v; want %v", result, expected)
+	}
+	if result, expected := ports.Snapshot(), []uint16{80, 443, 8080}; !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected ports; got %v; want %v", result, expected)
+	}
+	if result, expected := ips.String(), "127.0.0.1,::1"; result != expected {
+		t.Fatalf("unexpected ips; got %q; want %q", result, expected)
+	}
+	if result, expected := addrs.GetOptionalArg(5), netip.MustParseAddr("10.0.0.1"); result != expected {
+		t.Fatalf("unexpected addr; got %s; want %s", result, expected)
+	}
+
+	f := func(a flag.Value, value string) {
+		t.Helper()
+		if err := a.Set(value); err == nil {
+			t.Fatalf("expecting non-nil error for %q", value)
+		}
+	}
+	f(ports, "65536")
+	f(ports, "-1")
+	f(ips, "foo")
+	f(addrs, "foo")
+	if result, expected := ports.Snapshot(), []uint16{80, 443, 8080}; !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected ports after the rejected Set; got %v; want %v", result, expected)
+	}
+}
+
+func TestArrayURL(t *testing.T) {
+	urls := NewSetArray[*url.URL](NewSet("test", flag.ContinueOnError), "urls", "")
+	if err := urls.Set("http://foo/bar,https://baz"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result, expected := urls.String(), "http://foo/bar,https://baz"; result != expected {
+		t.Fatalf("unexpected urls; got %q; want %q", result, expected)
+	}
+	if err := urls.Set("http://[::1"); err == nil {
+		t.Fatalf("expecting non-nil error for invalid url")
+	}
+}
+
+type point struct {
+	x, y int
+}
+
+func TestRegisterArrayType(t *testing.T) {
+	RegisterArrayType(func(s string) (point, error) {
+		var p point
+		if _, err := fmt.Sscanf(s, "%d:%d", &p.x, &p.y); err != nil {
+			return p, fmt.Errorf("cannot parse point %q: %w", s, err)
+		}
+		return p, nil
+	}, func(p point) string {
+		return fmt.Sprintf("%d:%d", p.x, p.y)
+	})
+	s := NewSet("test", flag.ContinueOnError)
+	points := NewSetArray[point](s, "points", "the points")
+	if err := s.Parse([]string{"-points=1:2,3:4"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result, expected := points.Snapshot(), []point{{1, 2}, {3, 4}}; !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected points; got %v; want %v", result, expected)
+	}
+	if result, expected := points.String(), "1:2,3:4"; result != expected {
+		t.Fatalf("unexpected String(); got %q; want %q", result, expected)
+	}
+	if typ := flagTypeName(s.FlagSet().Lookup("points")); typ != "array of flagx.point" {
+		t.Fatalf("unexpected type name %q", typ)
+	}
+}
+
+func TestArrayUnsupportedType(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(fmt.Sprint(r), "register it via RegisterArrayType") {
+			t.Fatalf("unexpected panic: %v", r)
+		}
+	}()
+	NewSetArray[chan int](NewSet("test", flag.ContinueOnError), "chans", "")
+}
+
+func TestArrayIsBoolFlag(t *testing.T) {
+	if !(&ArrayBool{}).IsBoolFlag() {
+		t.Fatalf("ArrayBool must be bool flag")
+	}
+	if (&ArrayString{}).IsBoolFlag() || (&Array[uint8]{}).IsBoolFlag() {
+		t.Fatalf("non-bool arrays mustn't be bool flags")
+	}
+}
@@ -2,8 +2,11 @@ package flagx
 
 import (
 	"flag"
+	"fmt"
+	"io"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -308,7 +311,7 @@ func TestArrayBytes(t *testing.T) {
 	expected := []int64{10000000, 23, 10240}
 	result := make([]int64, len(fooFlagBytes))
 	for i, b := range fooFlagBytes {
-		result[i] = b.N
+		result[i] = b.Load()
 	}
 	if !reflect.DeepEqual(expected, result) {
 		t.Fatalf("unexpected flag values; got\n%d\nwant\n%d", result, expected)
@@ -322,7 +325,7 @@ func TestArrayBytes_Set(t *testing.T) {
 		_ = a.Set(s)
 		values := make([]int64, len(a))
 		for i, v := range a {
-			values[i] = v.N
+			values[i] = v.Load()
 		}
 		if !reflect.DeepEqual(values, expectedValues) {
 			t.Fatalf("unexpected values parsed;\ngot\n%d\nwant\n%d", values, expectedValues)
@@ -364,3 +367,143 @@ func TestArrayBytes_String(t *testing.T) {
 	f("10.5KiB,1")
 	f("-5,1,123MB")
 }
+
+func TestArrayStringConcurrentSnapshot(t *testing.T) {
+	var a ArrayString
+	if err := a.replace("a,b"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			x := a.Snapshot()
+			if !reflect.DeepEqual(x, []string{"a", "b"}) && !reflect.DeepEqual(x, []string{"c"}) {
+				panic(fmt.Errorf("unexpected snapshot: %q", x))
+			}
+			_ = a.String()
+			_ = a.GetOptionalArg(1)
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		v := "a,b"
+		if i%2 == 0 {
+			v = "c"
+		}
+		if err := a.replace(v); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	<-done
+}
+
+func TestArrayDuration_SetExtendedSyntax(t *testing.T) {
+	f := func(s string, expectedValues []time.Duration) {
+		t.Helper()
+		var a ArrayDuration
+		if err := a.Set(s); err != nil {
+			t.Fatalf("unexpected error in a.Set(%q): %s", s, err)
+		}
+		if !reflect.DeepEqual([]time.Duration(a), expectedValues) {
+			t.Fatalf("unexpected values parsed;\ngot\n%q\nwant\n%q", a, expectedValues)
+		}
+	}
+	f("30d,1y", []time.Duration{30 * 24 * time.Hour, 365 * 24 * time.Hour})
+	f("1w", []time.Duration{7 * 24 * time.Hour})
+	f("2h5m", []time.Duration{2*time.Hour + 5*time.Minute})
+	f("90,1.5", []time.Duration{90 * time.Second, 1500 * time.Millisecond})
+	f("1.5ms,100us", []time.Duration{1500 * time.Microsecond, 100 * time.Microsecond})
+}
+
+func TestArrayDuration_SetFailure(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		var a ArrayDuration
+		if err := a.Set(s); err == nil {
+			t.Fatalf("expecting non-nil error in a.Set(%q)", s)
+		}
+	}
+	f("foo")
+	f("1h,foo")
+	f("-1h")
+	f("1h,-5")
+}
+
+func TestNewArrayDuration(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	retention := s.NewArrayDuration("retention", "test")
+	offsets := s.NewArrayDuration("offset", "test", AllowNegative())
+	limited := s.NewArrayDuration("limited", "test", MaxDuration("1d"))
+	s.FlagSet().SetOutput(io.Discard)
+
+	if err := s.Parse([]string{"-retention=30d,1y", "-retention=2h5m", "-offset=-1h,2h-5m,-90"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// The values are printed in the form they were set.
+	if got, want := s.FlagSet().Lookup("retention").Value.String(), "30d,1y,2h5m"; got != want {
+		t.Fatalf("unexpected string; got %q; want %q", got, want)
+	}
+	expectedRetention := []time.Duration{30 * 24 * time.Hour, 365 * 24 * time.Hour, 2*time.Hour + 5*time.Minute}
+	if got := retention.Snapshot(); !reflect.DeepEqual(got, expectedRetention) {
+		t.Fatalf("unexpected values; got %v; want %v", got, expectedRetention)
+	}
+	if got, want := s.FlagSet().Lookup("offset").Value.String(), "-1h,2h-5m,-90"; got != want {
+		t.Fatalf("unexpected string; got %q; want %q", got, want)
+	}
+	expectedOffsets := []time.Duration{-time.Hour, time.Hour + 55*time.Minute, -90 * time.Second}
+	if got := offsets.Snapshot(); !reflect.DeepEqual(got, expectedOffsets) {
+		t.Fatalf("unexpected values; got %v; want %v", got, expectedOffsets)
+	}
+
+	if err := s.FlagSet().Set("limited", "1h"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := s.FlagSet().Set("limited", "2d"); err == nil {
+		t.Fatalf("expecting non-nil error for the value exceeding MaxDuration")
+	}
+	if got, want := limited.Snapshot(), []time.Duration{time.Hour}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected values; got %v; want %v", got, want)
+	}
+}
+
+func TestArrayDurationConcurrentString(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	a := s.NewArrayDuration("retention", "test", MaxDuration("1w"))
+	v := s.FlagSet().Lookup("retention").Value.(*arrayDurationValue)
+	if err := v.replace("1d"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			if s := v.String(); s != "1d" && s != "2h,3h" {
+				panic(fmt.Errorf("unexpected string: %q", s))
+			}
+			_ = a.Snapshot()
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		value := "1d"
+		if i%2 == 0 {
+			value = "2h,3h"
+		}
+		if err := v.replace(value); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	<-done
+}
+
+func TestArrayDurationCompletion(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	s.NewArrayDuration("retentions", "the retentions")
+	var sb strings.Builder
+	if err := s.WriteCompletion(&sb, "fish", "app"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := `complete -c 'app' -o 'retentions' -d 'the retentions' -r -f -a '(__flagx_app_units ms s m h d w y)'`
+	if !strings.Contains(sb.String(), expected) {
+		t.Fatalf("cannot find %q in completion:\n%s", expected, sb.String())
+	}
+}
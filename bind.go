@@ -0,0 +1,343 @@
+package flagx
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Bind registers a flag for every leaf field of the struct pointed to by v.
+//
+// Flag names are derived from the struct nesting and joined by dots, so the
+// `Port` field of the `Server` struct field becomes `server.port`.
+// The name of every level may be overridden with `flag:"name"` tag and falls back
+// to `yaml:"name"` tag and then to the lowercased field name. Fields tagged
+// with `flag:"-"` are skipped. Fields of embedded structs without tags are bound
+// without an additional prefix.
+//
+// The following tags are supported on leaf fields:
+//
+//	default:"..." - the default value; the current field value is used if missing.
+//	                Values set for slice and Array* fields replace the default value instead of being appended to it
+//	usage:"..."   - the flag description
+//	env:"..."     - the environment variable name, which overrides the one derived from the flag name
+//
+// Leaf fields may be of any type implementing flag.Value (for example Bytes, Duration
+// or Array* types from this package), basic Go types (string, bool, ints, uints, floats),
+// time.Duration, or []string, []int, []bool and []time.Duration slices.
+// time.Duration values are parsed the same way as Duration flag values, i.e. `1d` or `2h5m`.
+//
+// Flags point directly to the struct fields, so parsed values are written into v by Parse.
+// Fields of basic types and slices aren't synchronized, so use Bytes, Duration and Array* field types
+// for fields, which are read concurrently with Reload.
+func Bind(v any) error {
+	return CommandLine.Bind(v)
+}
+
+// BindFlagSet is like Bind, but registers flags at the given fs.
+func BindFlagSet(fs *flag.FlagSet, v any) error {
+	return setFor(fs).Bind(v)
+}
+
+// Bind registers a flag for every leaf field of the struct pointed to by v.
+//
+// See Bind function for details.
+func (s *Set) Bind(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot bind flags to %T; expecting non-nil pointer to struct", v)
+	}
+	return s.bindStruct(rv.Elem(), "")
+}
+
+func (s *Set) bindStruct(rv reflect.Value, prefix string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, ok := bindFieldName(sf)
+		if !ok {
+			continue
+		}
+		if prefix != "" && name != "" {
+			name = prefix + "." + name
+		} else if name == "" {
+			name = prefix
+		}
+		fv := rv.Field(i)
+		value := bindFieldValue(fv)
+		if value == nil {
+			if fv.Kind() == reflect.Struct {
+				if err := s.bindStruct(fv, name); err != nil {
+					return err
+				}
+				continue
+			}
+			return fmt.Errorf("cannot bind field %s.%s of unsupported type %s", rt.Name(), sf.Name, sf.Type)
+		}
+		if name == "" {
+			return fmt.Errorf("cannot bind field %s.%s: empty flag name", rt.Name(), sf.Name)
+		}
+		if isArrayValue(value) {
+			// Array values must be replaced by the first Set call instead of being appended to the default value.
+			value = newDefaultArrayValue(value)
+		}
+		if def, ok := sf.Tag.Lookup("default"); ok {
+			setDefault := value.Set
+			if ds, ok := value.(defaultSetter); ok {
+				setDefault = ds.setDefault
+			}
+			if err := setDefault(def); err != nil {
+				return fmt.Errorf("cannot set default value %q for flag %s: %w", def, name, err)
+			}
+		}
+		if env := sf.Tag.Get("env"); env != "" {
+			s.envFlagNames[name] = env
+		}
+		s.fs.Var(value, name, sf.Tag.Get("usage")+valueHelp(value))
+	}
+	return nil
+}
+
+// defaultSetter is implemented by flag values, which accumulate values on Set calls, such as Array* flags.
+type defaultSetter interface {
+	// setDefault sets the default value, which is replaced by the next Set call.
+	setDefault(value string) error
+}
+
+// bindFieldName returns the flag name segment for sf.
+//
+// An empty name is returned for untagged embedded structs, which are flattened into the parent.
+func bindFieldName(sf reflect.StructField) (string, bool) {
+	tag, ok := sf.Tag.Lookup("flag")
+	if !ok {
+		tag, ok = sf.Tag.Lookup("yaml")
+	}
+	if ok {
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			return "", false
+		}
+		if name != "" {
+			return name, true
+		}
+	}
+	if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+		return "", true
+	}
+	return strings.ToLower(sf.Name), true
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// bindFieldValue returns flag.Value pointing to fv, or nil if fv cannot be bound as a single flag.
+func bindFieldValue(fv reflect.Value) flag.Value {
+	p := fv.Addr().Interface()
+	if v, ok := p.(flag.Value); ok {
+		return v
+	}
+	switch p := p.(type) {
+	case *[]string:
+		return (*ArrayString)(p)
+	case *[]int:
+		return (*ArrayInt)(p)
+	case *[]bool:
+		return (*ArrayBool)(p)
+	case *[]time.Duration:
+		return &arrayDurationValue{a: (*ArrayDuration)(p)}
+	}
+	if fv.Type() == durationType {
+		return &reflectValue{v: fv}
+	}
+	switch fv.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return &reflectValue{v: fv}
+	}
+	return nil
+}
+
+// defaultArrayValue is a flag.Value for slice and Array* struct fields.
+//
+// The field value set before Bind and the value from `default` tag are replaced by the first Set call
+// instead of being appended to.
+type defaultArrayValue struct {
+	v interface {
+		flag.Value
+		replacer
+	}
+
+	// isDefault is set to true until the first Set call.
+	isDefault atomic.Bool
+}
+
+func newDefaultArrayValue(v flag.Value) *defaultArrayValue {
+	dv := &defaultArrayValue{
+		v: v.(interface {
+			flag.Value
+			replacer
+		}),
+	}
+	dv.isDefault.Store(true)
+	return dv
+}
+
+// flagValue returns the flag value holding the field values.
+func (dv *defaultArrayValue) flagValue() flag.Value {
+	return dv.v
+}
+
+// IsBoolFlag implements flag.IsBoolFlag interface
+func (dv *defaultArrayValue) IsBoolFlag() bool {
+	bf, ok := dv.v.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+// String implements flag.Value interface
+func (dv *defaultArrayValue) String() string {
+	if dv.v == nil {
+		// flag.isZeroValue calls String on zero value.
+		return ""
+	}
+	return dv.v.String()
+}
+
+// Set implements flag.Value interface
+func (dv *defaultArrayValue) Set(value string) error {
+	if !dv.isDefault.Load() {
+		return dv.v.Set(value)
+	}
+	if err := dv.v.replace(value); err != nil {
+		return err
+	}
+	dv.isDefault.Store(false)
+	return nil
+}
+
+func (dv *defaultArrayValue) replace(value string) error {
+	if err := dv.v.replace(value); err != nil {
+		return err
+	}
+	dv.isDefault.Store(false)
+	return nil
+}
+
+// setDefault sets the default value, which is replaced by the next Set call.
+func (dv *defaultArrayValue) setDefault(value string) error {
+	return dv.v.replace(value)
+}
+
+// unwrapValue returns the flag value holding the values of v.
+//
+// It differs from v for ArrayDuration flags and for slice and Array* struct fields bound via Bind.
+func unwrapValue(v flag.Value) flag.Value {
+	for {
+		uv, ok := v.(interface{ flagValue() flag.Value })
+		if !ok {
+			return v
+		}
+		v = uv.flagValue()
+	}
+}
+
+// valueHelp returns the description suffix for v, which is used by the corresponding New* functions.
+func valueHelp(v flag.Value) string {
+	v = unwrapValue(v)
+	if r, ok := v.(*reflectValue); ok && r.v.Type() == durationType {
+		return durationHelp
+	}
+	switch v.(type) {
+	case *Bytes:
+		return bytesHelp
+	case *Duration:
+		return durationHelp
+	case *ArrayBytes:
+		return arrayBytesHelp + arrayHelp
+	case *ArrayString, *ArrayInt, *ArrayBool, *ArrayDuration:
+		return arrayHelp
+	}
+	return ""
+}
+
+// reflectValue is a flag.Value for struct fields of basic types.
+type reflectValue struct {
+	v reflect.Value
+}
+
+// IsBoolFlag implements flag.IsBoolFlag interface
+func (r *reflectValue) IsBoolFlag() bool {
+	return r.v.IsValid() && r.v.Kind() == reflect.Bool
+}
+
+// String implements flag.Value interface
+func (r *reflectValue) String() string {
+	if !r.v.IsValid() {
+		// flag.isZeroValue calls String on zero value.
+		return ""
+	}
+	if r.v.Type() == durationType {
+		return time.Duration(r.v.Int()).String()
+	}
+	switch r.v.Kind() {
+	case reflect.String:
+		return r.v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(r.v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(r.v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(r.v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(r.v.Float(), 'g', -1, r.v.Type().Bits())
+	}
+	return ""
+}
+
+// Set implements flag.Value interface
+func (r *reflectValue) Set(value string) error {
+	if r.v.Type() == durationType {
+		d, err := parseDuration(value, false)
+		if err != nil {
+			return err
+		}
+		r.v.SetInt(int64(d))
+		return nil
+	}
+	switch r.v.Kind() {
+	case reflect.String:
+		r.v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		r.v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 0, r.v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		r.v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 0, r.v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		r.v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, r.v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		r.v.SetFloat(f)
+	}
+	return nil
+}
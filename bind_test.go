@@ -0,0 +1,184 @@
+package flagx
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBindFlagSet(t *testing.T) {
+	var cfg struct {
+		Name string `usage:"the name"`
+		Log  struct {
+			Level string `yaml:"level" default:"info"`
+		} `yaml:"log"`
+		Server struct {
+			Port    int           `default:"80"`
+			Addr    string        `flag:"address" env:"CUSTOM_SERVER_ADDR"`
+			TLS     bool          `flag:"tls"`
+			Timeout time.Duration `default:"5s"`
+			Skipped string        `flag:"-"`
+		}
+		Limit   Bytes    `default:"1KiB"`
+		Retain  Duration `default:"30d"`
+		Tags    []string `default:"a,b"`
+		Ports   ArrayInt `flag:"ports"`
+		Enabled bool
+	}
+	cfg.Name = "app"
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := BindFlagSet(fs, &cfg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) {
+		names = append(names, f.Name)
+	})
+	expectedNames := []string{
+		"enabled", "limit", "log.level", "name", "ports", "retain",
+		"server.address", "server.port", "server.timeout", "server.tls", "tags",
+	}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Fatalf("unexpected flag names;\ngot\n%q\nwant\n%q", names, expectedNames)
+	}
+	if v := fs.Lookup("name").DefValue; v != "app" {
+		t.Fatalf("unexpected default value for name flag; got %q; want %q", v, "app")
+	}
+
+	t.Setenv("CUSTOM_SERVER_ADDR", ":8080")
+	ParseFlagSet(fs, []string{"-server.port=443", "-server.tls", "-limit=2MiB", "-ports=1,2", "-enabled"})
+
+	if cfg.Name != "app" || cfg.Log.Level != "info" {
+		t.Fatalf("unexpected default values: %q, %q", cfg.Name, cfg.Log.Level)
+	}
+	if cfg.Server.Port != 443 || !cfg.Server.TLS || cfg.Server.Timeout != 5*time.Second {
+		t.Fatalf("unexpected server values: %+v", cfg.Server)
+	}
+	if cfg.Server.Addr != ":8080" {
+		t.Fatalf("unexpected value read from env; got %q; want %q", cfg.Server.Addr, ":8080")
+	}
+	if cfg.Limit.Load() != 2*1024*1024 || cfg.Retain.Load().Milliseconds() != 30*24*3600*1000 {
+		t.Fatalf("unexpected limit or retain values: %d, %d", cfg.Limit.Load(), cfg.Retain.Load().Milliseconds())
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"a", "b"}) || !reflect.DeepEqual([]int(cfg.Ports), []int{1, 2}) {
+		t.Fatalf("unexpected array values: %q, %d", cfg.Tags, cfg.Ports)
+	}
+	if !cfg.Enabled {
+		t.Fatalf("expecting enabled to be set")
+	}
+}
+
+func TestBindDuration(t *testing.T) {
+	f := func(args []string, expectedTimeout, expectedRetention time.Duration) {
+		t.Helper()
+		var cfg struct {
+			Timeout   time.Duration `default:"1d"`
+			Retention time.Duration
+		}
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		if err := BindFlagSet(fs, &cfg); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ParseFlagSet(fs, args)
+		if cfg.Timeout != expectedTimeout || cfg.Retention != expectedRetention {
+			t.Fatalf("unexpected values; got %s, %s; want %s, %s", cfg.Timeout, cfg.Retention, expectedTimeout, expectedRetention)
+		}
+	}
+	f(nil, 24*time.Hour, 0)
+	f([]string{"-timeout=1w", "-retention=2h5m"}, 7*24*time.Hour, 2*time.Hour+5*time.Minute)
+	f([]string{"-retention=1.5ms"}, 24*time.Hour, 1500*time.Microsecond)
+}
+
+func TestBindArrayDefaults(t *testing.T) {
+	type config struct {
+		Tags      []string        `default:"a,b"`
+		Ports     ArrayInt        `default:"80,443"`
+		Retention []time.Duration `default:"1d"`
+		Hosts     []string
+	}
+	f := func(args []string, expected *config) {
+		t.Helper()
+		var cfg config
+		cfg.Hosts = []string{"localhost"}
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		if err := BindFlagSet(fs, &cfg); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ParseFlagSet(fs, args)
+		if !reflect.DeepEqual(cfg.Tags, expected.Tags) || !reflect.DeepEqual(cfg.Ports, expected.Ports) ||
+			!reflect.DeepEqual(cfg.Retention, expected.Retention) || !reflect.DeepEqual(cfg.Hosts, expected.Hosts) {
+			t.Fatalf("unexpected values; got %q, %d, %s, %q; want %q, %d, %s, %q",
+				cfg.Tags, cfg.Ports, cfg.Retention, cfg.Hosts, expected.Tags, expected.Ports, expected.Retention, expected.Hosts)
+		}
+	}
+
+	// Default values
+	f(nil, &config{
+		Tags:      []string{"a", "b"},
+		Ports:     ArrayInt{80, 443},
+		Retention: []time.Duration{24 * time.Hour},
+		Hosts:     []string{"localhost"},
+	})
+
+	// Command-line values replace the default values
+	f([]string{"-tags=c", "-tags=d", "-ports=8080", "-retention=1w", "-hosts=example.com"}, &config{
+		Tags:      []string{"c", "d"},
+		Ports:     ArrayInt{8080},
+		Retention: []time.Duration{7 * 24 * time.Hour},
+		Hosts:     []string{"example.com"},
+	})
+
+	// Env values replace the default values
+	t.Setenv("TAGS", "c")
+	t.Setenv("PORTS", "8080,8443")
+	t.Setenv("HOSTS", "example.com")
+	f(nil, &config{
+		Tags:      []string{"c"},
+		Ports:     ArrayInt{8080, 8443},
+		Retention: []time.Duration{24 * time.Hour},
+		Hosts:     []string{"example.com"},
+	})
+}
+
+func TestBindFlagSetEnvPerFlagSet(t *testing.T) {
+	var cfgA struct {
+		Addr string `flag:"addr" env:"BIND_TEST_ADDR_A"`
+	}
+	var cfgB struct {
+		Addr string `flag:"addr" env:"BIND_TEST_ADDR_B"`
+	}
+	fsA := flag.NewFlagSet("a", flag.ContinueOnError)
+	fsB := flag.NewFlagSet("b", flag.ContinueOnError)
+	if err := BindFlagSet(fsA, &cfgA); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := BindFlagSet(fsB, &cfgB); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	t.Setenv("BIND_TEST_ADDR_A", "a")
+	t.Setenv("BIND_TEST_ADDR_B", "b")
+	ParseFlagSet(fsA, nil)
+	ParseFlagSet(fsB, nil)
+	if cfgA.Addr != "a" || cfgB.Addr != "b" {
+		t.Fatalf("unexpected values; got %q, %q; want %q, %q", cfgA.Addr, cfgB.Addr, "a", "b")
+	}
+}
+
+func TestBindFlagSetFailure(t *testing.T) {
+	f := func(v any) {
+		t.Helper()
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		if err := BindFlagSet(fs, v); err == nil {
+			t.Fatalf("expecting non-nil error when binding %T", v)
+		}
+	}
+	f(nil)
+	f(struct{}{})
+	f(new(int))
+	f(&struct{ M map[string]string }{})
+	f(&struct {
+		N int `default:"foo"`
+	}{})
+}
@@ -1,126 +1,173 @@
 package flagx
 
 import (
-	"flag"
 	"fmt"
 	"math"
 	"strconv"
 	"strings"
+	"sync/atomic"
 )
 
-// NewBytes returns new `bytes` flag with the given name, defaultValue and description.
-func NewBytes(name string, defaultValue int64, description string) *Bytes {
-	description += "\nSupports the following optional suffixes for `size` values: KB, MB, GB, TB, KiB, MiB, GiB, TiB." + envHelp(name)
-	b := Bytes{
-		N:           defaultValue,
-		valueString: fmt.Sprintf("%d", defaultValue),
+// NewBytes returns new `bytes` flag with the given name, defaultValue, description and optional opts.
+func NewBytes(name string, defaultValue int64, description string, opts ...Option) *Bytes {
+	return CommandLine.NewBytes(name, defaultValue, description, opts...)
+}
+
+// NewBytes returns new `bytes` flag with the given name, defaultValue, description and optional opts.
+//
+// Min, Max and Validate[int64] options are supported.
+func (s *Set) NewBytes(name string, defaultValue int64, description string, opts ...Option) *Bytes {
+	validators, help := applyOptions(opts).intChecks(name, formatBytes)
+	description += bytesHelp + help
+	b := &Bytes{
+		N:          defaultValue,
+		validators: validators,
 	}
-	flag.Var(&b, name, description)
-	return &b
+	if err := validateInt(validators, defaultValue); err != nil {
+		panic(fmt.Sprintf("BUG: invalid default value %d for flag %s: %s", defaultValue, name, err))
+	}
+	b.store(defaultValue, fmt.Sprintf("%d", defaultValue))
+	s.fs.Var(b, name, description)
+	return b
 }
 
+const bytesHelp = "\nSupports the following optional suffixes for `size` values: KB, MB, GB, TB, KiB, MiB, GiB, TiB."
+
 // Bytes is a flag for holding size in bytes.
 //
 // It supports the following optional suffixes for values: KB, MB, GB, TB, KiB, MiB, GiB, TiB.
+//
+// Use Load for reading the value from goroutines running concurrently with Set, i.e. during Reload.
 type Bytes struct {
 	// N contains parsed value for the given flag.
+	//
+	// N is updated by Set without synchronization, so it mustn't be read while Set or Reload may run
+	// in another goroutine.
+	//
+	// Deprecated: use Load instead.
 	N int64
 
+	state atomic.Pointer[bytesState]
+
+	validators []func(n int64) error
+}
+
+type bytesState struct {
+	n           int64
 	valueString string
 }
 
+// Load returns the stored value.
+//
+// It is safe calling Load concurrently with Set.
+func (b *Bytes) Load() int64 {
+	if st := b.state.Load(); st != nil {
+		return st.n
+	}
+	return b.N
+}
+
 // IntN returns the stored value capped by int type.
+//
+// It is safe calling IntN concurrently with Set.
 func (b *Bytes) IntN() int {
-	if b.N > math.MaxInt {
+	n := b.Load()
+	if n > math.MaxInt {
 		return math.MaxInt
 	}
-	if b.N < math.MinInt {
+	if n < math.MinInt {
 		return math.MinInt
 	}
-	return int(b.N)
+	return int(n)
 }
 
 // String implements flag.Value interface
 func (b *Bytes) String() string {
-	return b.valueString
+	if st := b.state.Load(); st != nil {
+		return st.valueString
+	}
+	return ""
+}
+
+func (b *Bytes) store(n int64, valueString string) {
+	b.N = n
+	b.state.Store(&bytesState{
+		n:           n,
+		valueString: valueString,
+	})
 }
 
 // Set implements flag.Value interface
 func (b *Bytes) Set(value string) error {
 	value = normalizeBytesString(value)
+	n, err := parseBytes(value)
+	if err != nil {
+		return err
+	}
+	if err := validateInt(b.validators, n); err != nil {
+		return err
+	}
+	b.store(n, value)
+	return nil
+}
+
+// parseBytes parses normalized value with optional size suffix.
+func parseBytes(value string) (int64, error) {
 	switch {
 	case strings.HasSuffix(value, "KB"):
 		f, err := strconv.ParseFloat(value[:len(value)-2], 64)
 		if err != nil {
-			return err
+			return 0, err
 		}
-		b.N = int64(f * 1000)
-		b.valueString = value
-		return nil
+		return int64(f * 1000), nil
 	case strings.HasSuffix(value, "MB"):
 		f, err := strconv.ParseFloat(value[:len(value)-2], 64)
 		if err != nil {
-			return err
+			return 0, err
 		}
-		b.N = int64(f * 1000 * 1000)
-		b.valueString = value
-		return nil
+		return int64(f * 1000 * 1000), nil
 	case strings.HasSuffix(value, "GB"):
 		f, err := strconv.ParseFloat(value[:len(value)-2], 64)
 		if err != nil {
-			return err
+			return 0, err
 		}
-		b.N = int64(f * 1000 * 1000 * 1000)
-		b.valueString = value
-		return nil
+		return int64(f * 1000 * 1000 * 1000), nil
 	case strings.HasSuffix(value, "TB"):
 		f, err := strconv.ParseFloat(value[:len(value)-2], 64)
 		if err != nil {
-			return err
+			return 0, err
 		}
-		b.N = int64(f * 1000 * 1000 * 1000 * 1000)
-		b.valueString = value
-		return nil
+		return int64(f * 1000 * 1000 * 1000 * 1000), nil
 	case strings.HasSuffix(value, "KiB"):
 		f, err := strconv.ParseFloat(value[:len(value)-3], 64)
 		if err != nil {
-			return err
+			return 0, err
 		}
-		b.N = int64(f * 1024)
-		b.valueString = value
-		return nil
+		return int64(f * 1024), nil
 	case strings.HasSuffix(value, "MiB"):
 		f, err := strconv.ParseFloat(value[:len(value)-3], 64)
 		if err != nil {
-			return err
+			return 0, err
 		}
-		b.N = int64(f * 1024 * 1024)
-		b.valueString = value
-		return nil
+		return int64(f * 1024 * 1024), nil
 	case strings.HasSuffix(value, "GiB"):
 		f, err := strconv.ParseFloat(value[:len(value)-3], 64)
 		if err != nil {
-			return err
+			return 0, err
 		}
-		b.N = int64(f * 1024 * 1024 * 1024)
-		b.valueString = value
-		return nil
+		return int64(f * 1024 * 1024 * 1024), nil
 	case strings.HasSuffix(value, "TiB"):
 		f, err := strconv.ParseFloat(value[:len(value)-3], 64)
 		if err != nil {
-			return err
+			return 0, err
 		}
-		b.N = int64(f * 1024 * 1024 * 1024 * 1024)
-		b.valueString = value
-		return nil
+		return int64(f * 1024 * 1024 * 1024 * 1024), nil
 	default:
 		f, err := strconv.ParseFloat(value, 64)
 		if err != nil {
-			return err
+			return 0, err
 		}
-		b.N = int64(f)
-		b.valueString = value
-		return nil
+		return int64(f), nil
 	}
 }
 
@@ -1,6 +1,7 @@
 package flagx
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -30,8 +31,8 @@ func TestBytesSetSuccess(t *testing.T) {
 		if err := b.Set(value); err != nil {
 			t.Fatalf("unexpected error in b.Set(%q): %s", value, err)
 		}
-		if b.N != expectedResult {
-			t.Fatalf("unexpected result; got %d; want %d", b.N, expectedResult)
+		if b.Load() != expectedResult {
+			t.Fatalf("unexpected result; got %d; want %d", b.Load(), expectedResult)
 		}
 		valueString := b.String()
 		valueExpected := normalizeBytesString(value)
@@ -54,3 +55,32 @@ func TestBytesSetSuccess(t *testing.T) {
 	f("0.25GB", 0.25*1000*1000*1000)
 	f("1.25TB", 1.25*1000*1000*1000*1000)
 }
+
+func TestBytesConcurrentLoad(t *testing.T) {
+	var b Bytes
+	if err := b.Set("1KiB"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			if n := b.Load(); n != 1024 && n != 2048 {
+				panic(fmt.Errorf("unexpected value loaded: %d", n))
+			}
+			if s := b.String(); s != "1KiB" && s != "2KiB" {
+				panic(fmt.Errorf("unexpected string loaded: %q", s))
+			}
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		v := "1KiB"
+		if i%2 == 0 {
+			v = "2KiB"
+		}
+		if err := b.Set(v); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	<-done
+}
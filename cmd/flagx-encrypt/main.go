@@ -0,0 +1,51 @@
+// flagx-encrypt encrypts values for flags parsed by flagx.
+//
+// Usage:
+//
+//	flagx-encrypt -generate.key > /etc/app/flagx.key
+//	echo -n 'hunter2' | flagx-encrypt -key.file=/etc/app/flagx.key
+//
+// The value is read from stdin, so it doesn't appear in the shell history and the process list.
+// The key is read from FLAGX_ENCRYPTION_KEY env var if -key.file isn't set.
+// The printed `enc:...` value may be passed to the flag via any source.
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/cloudfly/flagx"
+)
+
+var (
+	keyFile     = flagx.NewString("key.file", "", "Path to the file with base64-encoded encryption key. The key is read from FLAGX_ENCRYPTION_KEY env var if empty")
+	generateKey = flagx.NewBool("generate.key", false, "Whether to print a new base64-encoded 32-byte encryption key and exit")
+)
+
+func main() {
+	flagx.Parse()
+	if *generateKey {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			log.Fatalf("cannot generate encryption key: %s", err)
+		}
+		fmt.Println(base64.StdEncoding.EncodeToString(key))
+		return
+	}
+
+	flagx.SetEncryptionKeyFile(*keyFile)
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		log.Fatalf("cannot read value from stdin: %s", err)
+	}
+	value, err := flagx.EncryptValue(strings.TrimRight(string(data), "\r\n"))
+	if err != nil {
+		log.Fatalf("cannot encrypt value: %s", err)
+	}
+	fmt.Println(value)
+}
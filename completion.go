@@ -0,0 +1,265 @@
+package flagx
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// WriteCompletion writes completion script for the given shell and CommandLine flags to w.
+//
+// See Set.WriteCompletion for details.
+func WriteCompletion(w io.Writer, shell, prog string) error {
+	return CommandLine.WriteCompletion(w, shell, prog)
+}
+
+// MarkFileFlag marks flags with the given names at CommandLine as accepting file paths.
+//
+// See Set.MarkFileFlag for details.
+func MarkFileFlag(names ...string) {
+	CommandLine.MarkFileFlag(names...)
+}
+
+// MarkFileFlag marks flags with the given names as accepting file paths,
+// so shell completion suggests file paths for them.
+//
+// -config.file flag is marked as accepting file paths by default.
+func (s *Set) MarkFileFlag(names ...string) {
+	if s.fileFlags == nil {
+		s.fileFlags = make(map[string]bool)
+	}
+	for _, name := range names {
+		s.fileFlags[name] = true
+	}
+}
+
+// choicesValue is implemented by flag values accepting a fixed set of values.
+//
+// The choices are used for shell completion.
+type choicesValue interface {
+	Choices() []string
+}
+
+var (
+	bytesSuffixes    = []string{"KB", "MB", "GB", "TB", "KiB", "MiB", "GiB", "TiB"}
+	durationSuffixes = []string{"ms", "s", "m", "h", "d", "w", "y"}
+)
+
+type completionKind int
+
+const (
+	completeAny completionKind = iota
+	completeBool
+	completeFile
+	completeChoices
+	completeSuffixes
+)
+
+// flagCompletion describes how to complete a single flag.
+type flagCompletion struct {
+	name        string
+	description string
+	kind        completionKind
+
+	// values contains choices for completeChoices and unit suffixes for completeSuffixes.
+	values []string
+}
+
+func (s *Set) flagCompletions() []flagCompletion {
+	var fcs []flagCompletion
+	s.fs.VisitAll(func(f *flag.Flag) {
+		_, usage := flag.UnquoteUsage(f)
+		usage, _, _ = strings.Cut(usage, "\n")
+		fc := flagCompletion{
+			name:        f.Name,
+			description: usage,
+		}
+		if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() {
+			fc.kind = completeBool
+			fc.values = []string{"true", "false"}
+		} else if s.fileFlags[f.Name] || f.Name == "config.file" {
+			fc.kind = completeFile
+		} else if cv, ok := f.Value.(choicesValue); ok {
+			fc.kind = completeChoices
+			fc.values = cv.Choices()
+		} else {
+			switch unwrapValue(f.Value).(type) {
+			case *Bytes, *ArrayBytes:
+				fc.kind = completeSuffixes
+				fc.values = bytesSuffixes
+			case *Duration, *ArrayDuration:
+				fc.kind = completeSuffixes
+				fc.values = durationSuffixes
+			}
+		}
+		fcs = append(fcs, fc)
+	})
+	return fcs
+}
+
+// WriteCompletion writes completion script for the given shell to w.
+//
+// The supported shells are `bash`, `zsh` and `fish`. prog is the name of the program to complete.
+//
+// The script completes flag names, `true` and `false` values for bool flags,
+// file paths for flags marked via MarkFileFlag, choices for flags implementing `Choices() []string`
+// and unit suffixes for Bytes and Duration flags.
+//
+// The script may be loaded in the following way:
+//
+//	source <(app -completion=bash)
+func (s *Set) WriteCompletion(w io.Writer, shell, prog string) error {
+	fcs := s.flagCompletions()
+	switch shell {
+	case "bash":
+		writeBashCompletion(w, prog, fcs)
+	case "zsh":
+		writeZshCompletion(w, prog, fcs)
+	case "fish":
+		writeFishCompletion(w, prog, fcs)
+	default:
+		return fmt.Errorf("unsupported shell %q; supported shells: bash, zsh, fish", shell)
+	}
+	return nil
+}
+
+var nonIdentRegexp = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+func completionFuncName(prog string) string {
+	return "_flagx_" + nonIdentRegexp.ReplaceAllString(prog, "_")
+}
+
+// shellQuote returns s quoted with single quotes.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func writeBashCompletion(w io.Writer, prog string, fcs []flagCompletion) {
+	fn := completionFuncName(prog)
+	var names, valueFlags []string
+	cases := make(map[string][]string)
+	for _, fc := range fcs {
+		names = append(names, "-"+fc.name)
+		if fc.kind != completeBool {
+			valueFlags = append(valueFlags, fc.name)
+		}
+		var action string
+		switch fc.kind {
+		case completeBool, completeChoices:
+			action = fmt.Sprintf(`COMPREPLY=($(compgen -W %s -- "$cur"))`, shellQuote(strings.Join(fc.values, " ")))
+		case completeFile:
+			action = `COMPREPLY=($(compgen -f -- "$cur"))`
+		case completeSuffixes:
+			action = fmt.Sprintf(`COMPREPLY=($(compgen -W "$(%s_units "$cur" %s)" -- "$cur"))`, fn, strings.Join(fc.values, " "))
+		default:
+			continue
+		}
+		cases[action] = append(cases[action], fc.name)
+	}
+
+	fmt.Fprintf(w, "# bash completion for %s\n\n", prog)
+	fmt.Fprintf(w, "%s_units() {\n", fn)
+	fmt.Fprintf(w, "\tlocal n=\"${1%%%%[!0-9.]*}\"\n")
+	fmt.Fprintf(w, "\t[[ -z \"$n\" ]] && return\n")
+	fmt.Fprintf(w, "\tshift\n")
+	fmt.Fprintf(w, "\tfor u in \"$@\"; do echo \"$n$u\"; done\n")
+	fmt.Fprintf(w, "}\n\n")
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintf(w, "\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"\" name=\"\"\n")
+	fmt.Fprintf(w, "\t[[ $COMP_CWORD -gt 0 ]] && prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprintf(w, "\tif [[ \"$cur\" == \"=\" ]]; then\n")
+	fmt.Fprintf(w, "\t\tname=\"$prev\"\n")
+	fmt.Fprintf(w, "\t\tcur=\"\"\n")
+	fmt.Fprintf(w, "\telif [[ \"$prev\" == \"=\" && $COMP_CWORD -gt 1 ]]; then\n")
+	fmt.Fprintf(w, "\t\tname=\"${COMP_WORDS[COMP_CWORD-2]}\"\n")
+	if len(valueFlags) > 0 {
+		fmt.Fprintf(w, "\telif [[ \"$prev\" == -* ]]; then\n")
+		fmt.Fprintf(w, "\t\tcase \"${prev#-}\" in\n")
+		fmt.Fprintf(w, "\t\t-%s|%s) name=\"$prev\" ;;\n", strings.Join(valueFlags, "|-"), strings.Join(valueFlags, "|"))
+		fmt.Fprintf(w, "\t\tesac\n")
+	}
+	fmt.Fprintf(w, "\tfi\n")
+	fmt.Fprintf(w, "\tif [[ -n \"$name\" ]]; then\n")
+	fmt.Fprintf(w, "\t\tname=\"${name#-}\"\n")
+	fmt.Fprintf(w, "\t\tname=\"${name#-}\"\n")
+	fmt.Fprintf(w, "\t\tcase \"$name\" in\n")
+	actions := make([]string, 0, len(cases))
+	for action := range cases {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		fmt.Fprintf(w, "\t\t%s) %s ;;\n", strings.Join(cases[action], "|"), action)
+	}
+	fmt.Fprintf(w, "\t\tesac\n")
+	fmt.Fprintf(w, "\t\treturn 0\n")
+	fmt.Fprintf(w, "\tfi\n")
+	fmt.Fprintf(w, "\tif [[ \"$cur\" == -* ]]; then\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(names, " ")))
+	fmt.Fprintf(w, "\tfi\n")
+	fmt.Fprintf(w, "}\n\n")
+	fmt.Fprintf(w, "complete -F %s %s\n", fn, shellQuote(prog))
+}
+
+var zshDescriptionReplacer = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`, `:`, `\:`)
+
+func writeZshCompletion(w io.Writer, prog string, fcs []flagCompletion) {
+	fn := completionFuncName(prog)
+	fmt.Fprintf(w, "#compdef %s\n\n", prog)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintf(w, "\t_arguments \\\n")
+	for i, fc := range fcs {
+		desc := zshDescriptionReplacer.Replace(fc.description)
+		var spec string
+		switch fc.kind {
+		case completeBool:
+			spec = fmt.Sprintf("-%s[%s]", fc.name, desc)
+		case completeFile:
+			spec = fmt.Sprintf("-%s=[%s]:file:_files", fc.name, desc)
+		case completeChoices:
+			spec = fmt.Sprintf("-%s=[%s]:value:(%s)", fc.name, desc, strings.Join(fc.values, " "))
+		case completeSuffixes:
+			spec = fmt.Sprintf("-%s=[%s]:value with optional %s suffix:", fc.name, desc, strings.Join(fc.values, ", "))
+		default:
+			spec = fmt.Sprintf("-%s=[%s]:value:", fc.name, desc)
+		}
+		sep := " \\"
+		if i == len(fcs)-1 {
+			sep = ""
+		}
+		fmt.Fprintf(w, "\t\t%s%s\n", shellQuote(spec), sep)
+	}
+	fmt.Fprintf(w, "}\n\n")
+	fmt.Fprintf(w, "compdef %s %s\n", fn, shellQuote(prog))
+}
+
+func writeFishCompletion(w io.Writer, prog string, fcs []flagCompletion) {
+	fn := strings.TrimPrefix(completionFuncName(prog), "_") + "_units"
+	fmt.Fprintf(w, "# fish completion for %s\n\n", prog)
+	fmt.Fprintf(w, "function __%s\n", fn)
+	fmt.Fprintf(w, "\tset -l n (commandline -ct | string replace -r '^-[^=]*=' '' | string match -r '^[0-9.]+')\n")
+	fmt.Fprintf(w, "\ttest -n \"$n\"; or return\n")
+	fmt.Fprintf(w, "\tfor u in $argv\n")
+	fmt.Fprintf(w, "\t\techo $n$u\n")
+	fmt.Fprintf(w, "\tend\n")
+	fmt.Fprintf(w, "end\n\n")
+	for _, fc := range fcs {
+		args := fmt.Sprintf("complete -c %s -o %s -d %s", shellQuote(prog), shellQuote(fc.name), shellQuote(fc.description))
+		switch fc.kind {
+		case completeBool:
+			args += " -f"
+		case completeFile:
+			args += " -r -F"
+		case completeChoices:
+			args += " -r -f -a " + shellQuote(strings.Join(fc.values, " "))
+		case completeSuffixes:
+			args += " -r -f -a " + shellQuote(fmt.Sprintf("(__%s %s)", fn, strings.Join(fc.values, " ")))
+		default:
+			args += " -r -f"
+		}
+		fmt.Fprintf(w, "%s\n", args)
+	}
+}
@@ -0,0 +1,118 @@
+package flagx
+
+import (
+	"bytes"
+	"flag"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+type testChoices string
+
+func (tc *testChoices) String() string         { return string(*tc) }
+func (tc *testChoices) Set(value string) error { *tc = testChoices(value); return nil }
+func (tc *testChoices) Choices() []string      { return []string{"debug", "info", "warn"} }
+
+func TestSetWriteCompletionBash(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash isn't available")
+	}
+	s := NewSet("test", flag.ContinueOnError)
+	s.NewBool("tls", false, "whether to use TLS")
+	s.NewString("tls.cert", "", "the TLS cert")
+	s.NewBytes("limit", 1024, "the size limit")
+	s.NewDuration("retention", "1d", "the retention")
+	s.NewString("server.addr", ":80", "the address")
+	var level testChoices
+	s.Var(&level, "log.level", "the log level")
+	s.MarkFileFlag("tls.cert")
+	var bb bytes.Buffer
+	if err := s.WriteCompletion(&bb, "bash", "my-app"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	script := bb.String()
+
+	f := func(words []string, expected string) {
+		t.Helper()
+		cmd := script + `
+complete() { :; }
+COMP_WORDS=(` + strings.Join(words, " ") + `)
+COMP_CWORD=$((${#COMP_WORDS[@]}-1))
+COMPREPLY=()
+_flagx_my_app
+echo "${COMPREPLY[*]}"
+`
+		out, err := exec.Command(bash, "-c", cmd).CombinedOutput()
+		if err != nil {
+			t.Fatalf("cannot run completion script: %s\n%s", err, out)
+		}
+		if result := strings.TrimSpace(string(out)); result != expected {
+			t.Fatalf("unexpected completion for %q;\ngot\n%s\nwant\n%s", words, result, expected)
+		}
+	}
+	f([]string{"my-app", "-tl"}, "-tls -tls.cert")
+	f([]string{"my-app", "-log"}, "-log.level")
+	f([]string{"my-app", "-tls", "=", "t"}, "true")
+	f([]string{"my-app", "-log.level", "=", ""}, "debug info warn")
+	f([]string{"my-app", "-log.level", "i"}, "info")
+	f([]string{"my-app", "-limit", "=", "64"}, "64KB 64MB 64GB 64TB 64KiB 64MiB 64GiB 64TiB")
+	f([]string{"my-app", "-retention", "=", "3"}, "3ms 3s 3m 3h 3d 3w 3y")
+	f([]string{"my-app", "-server.addr", "="}, "")
+	f([]string{"my-app", "-tls", "foo"}, "")
+}
+
+func TestSetWriteCompletion(t *testing.T) {
+	f := func(shell string, register func(s *Set), expected string) {
+		t.Helper()
+		s := NewSet("test", flag.ContinueOnError)
+		register(s)
+		var bb bytes.Buffer
+		if err := s.WriteCompletion(&bb, shell, "app"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !strings.Contains(bb.String(), expected) {
+			t.Fatalf("cannot find %q in %s completion:\n%s", expected, shell, bb.String())
+		}
+	}
+	noFlags := func(s *Set) {}
+	f("zsh", noFlags, "#compdef app")
+	f("zsh", noFlags, "compdef _flagx_app 'app'")
+	f("zsh", func(s *Set) {
+		s.NewBool("tls", false, "whether to use TLS")
+	}, `'-tls[whether to use TLS]'`)
+	f("zsh", func(s *Set) {
+		s.NewString("tls.cert", "", "path to TLS [cert]: it's a file\nsecond line")
+		s.MarkFileFlag("tls.cert")
+	}, `'-tls.cert=[path to TLS \[cert\]\: it'\''s a file]:file:_files'`)
+	f("zsh", func(s *Set) {
+		var level testChoices
+		s.Var(&level, "log.level", "the log level")
+	}, `'-log.level=[the log level]:value:(debug info warn)'`)
+	f("zsh", func(s *Set) {
+		s.NewBytes("limit", 1024, "the size limit")
+	}, `'-limit=[the size limit]:value with optional KB, MB, GB, TB, KiB, MiB, GiB, TiB suffix:'`)
+	f("zsh", func(s *Set) {
+		s.NewString("server.addr", ":80", "the address")
+	}, `'-server.addr=[the address]:value:'`)
+	f("fish", func(s *Set) {
+		s.NewBool("tls", false, "whether to use TLS")
+	}, `complete -c 'app' -o 'tls' -d 'whether to use TLS' -f`)
+	f("fish", func(s *Set) {
+		s.NewString("tls.cert", "", "path to TLS [cert]: it's a file\nsecond line")
+		s.MarkFileFlag("tls.cert")
+	}, `complete -c 'app' -o 'tls.cert' -d 'path to TLS [cert]: it'\''s a file' -r -F`)
+	f("fish", func(s *Set) {
+		var level testChoices
+		s.Var(&level, "log.level", "the log level")
+	}, `complete -c 'app' -o 'log.level' -d 'the log level' -r -f -a 'debug info warn'`)
+	f("fish", func(s *Set) {
+		s.NewDuration("retention", "1d", "the retention")
+	}, `complete -c 'app' -o 'retention' -d 'the retention' -r -f -a '(__flagx_app_units ms s m h d w y)'`)
+
+	var bb bytes.Buffer
+	if err := NewSet("test", flag.ContinueOnError).WriteCompletion(&bb, "powershell", "app"); err == nil {
+		t.Fatalf("expecting non-nil error for unsupported shell")
+	}
+}
@@ -0,0 +1,200 @@
+package flagx
+
+import (
+	"fmt"
+	"strings"
+)
+
+type constraintKind int
+
+const (
+	constraintRequired constraintKind = iota
+	constraintOneOf
+	constraintMutuallyExclusive
+	constraintRequiresIf
+)
+
+// constraint is a constraint on flag values registered via Required, OneOf, MutuallyExclusive or RequiresIf.
+type constraint struct {
+	kind constraintKind
+
+	// names contains flag names the constraint refers to.
+	//
+	// The first name is the dependent flag for constraintRequiresIf.
+	names []string
+}
+
+// ConstraintError is returned when flag values violate a constraint registered via Required,
+// OneOf, MutuallyExclusive or RequiresIf.
+type ConstraintError struct {
+	// Names contains names of flags violating the constraint.
+	Names []string
+
+	msg string
+}
+
+// Error implements error interface.
+func (e *ConstraintError) Error() string {
+	return e.msg
+}
+
+// Required registers flags with the given names at CommandLine as required.
+//
+// See Set.Required for details.
+func Required(names ...string) {
+	CommandLine.Required(names...)
+}
+
+// OneOf registers a constraint at CommandLine requiring exactly one of flags with the given names to be set.
+//
+// See Set.OneOf for details.
+func OneOf(names ...string) {
+	CommandLine.OneOf(names...)
+}
+
+// MutuallyExclusive registers a constraint at CommandLine allowing at most one of flags with the given names to be set.
+//
+// See Set.MutuallyExclusive for details.
+func MutuallyExclusive(names ...string) {
+	CommandLine.MutuallyExclusive(names...)
+}
+
+// RequiresIf registers a constraint at CommandLine requiring flags with the given names to be set if name is set.
+//
+// See Set.RequiresIf for details.
+func RequiresIf(name string, required ...string) {
+	CommandLine.RequiresIf(name, required...)
+}
+
+// Required registers flags with the given names as required.
+//
+// A flag is considered set if its value is read from any source, i.e. command line, env var or config file.
+// Constraints are validated at the end of Parse* calls, and all the violations are returned in *ParseError
+// as *ConstraintError.
+func (s *Set) Required(names ...string) {
+	for _, name := range names {
+		s.addConstraint(constraintRequired, name)
+	}
+}
+
+// OneOf registers a constraint requiring exactly one of flags with the given names to be set.
+//
+// See Required for details on constraint validation.
+func (s *Set) OneOf(names ...string) {
+	s.addConstraint(constraintOneOf, names...)
+}
+
+// MutuallyExclusive registers a constraint allowing at most one of flags with the given names to be set.
+//
+// See Required for details on constraint validation.
+func (s *Set) MutuallyExclusive(names ...string) {
+	s.addConstraint(constraintMutuallyExclusive, names...)
+}
+
+// RequiresIf registers a constraint requiring flags with the given names to be set if name is set,
+// i.e. RequiresIf("tls.cert", "tls.key") requires -tls.key if -tls.cert is set.
+//
+// See Required for details on constraint validation.
+func (s *Set) RequiresIf(name string, required ...string) {
+	s.addConstraint(constraintRequiresIf, append([]string{name}, required...)...)
+}
+
+func (s *Set) addConstraint(kind constraintKind, names ...string) {
+	s.constraints = append(s.constraints, constraint{
+		kind:  kind,
+		names: names,
+	})
+}
+
+// checkConstraints validates the registered constraints against the flags with the origins returned by origin.
+//
+// It appends *ConstraintError for every violated constraint to errs and returns the result.
+func (s *Set) checkConstraints(errs []error, origin func(name string) string) []error {
+	isSet := func(name string) bool {
+		return origin(name) != OriginDefault
+	}
+	describe := func(names []string) string {
+		a := make([]string, len(names))
+		for i, name := range names {
+			a[i] = "-" + name
+		}
+		return strings.Join(a, ", ")
+	}
+	describeSet := func(names []string) string {
+		a := make([]string, len(names))
+		for i, name := range names {
+			a[i] = fmt.Sprintf("-%s (from %s)", name, origin(name))
+		}
+		return strings.Join(a, ", ")
+	}
+	for _, c := range s.constraints {
+		var unknown []string
+		for _, name := range c.names {
+			if s.fs.Lookup(name) == nil {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) > 0 {
+			errs = append(errs, &ConstraintError{
+				Names: unknown,
+				msg:   fmt.Sprintf("cannot check constraint for unknown flags %s", describe(unknown)),
+			})
+			continue
+		}
+
+		var set []string
+		for _, name := range c.names {
+			if isSet(name) {
+				set = append(set, name)
+			}
+		}
+		switch c.kind {
+		case constraintRequired:
+			if len(set) == 0 {
+				errs = append(errs, &ConstraintError{
+					Names: c.names,
+					msg:   fmt.Sprintf("flag %s is required", describe(c.names)),
+				})
+			}
+		case constraintOneOf:
+			switch len(set) {
+			case 0:
+				errs = append(errs, &ConstraintError{
+					Names: c.names,
+					msg:   fmt.Sprintf("exactly one of flags %s must be set; got none", describe(c.names)),
+				})
+			case 1:
+			default:
+				errs = append(errs, &ConstraintError{
+					Names: set,
+					msg:   fmt.Sprintf("exactly one of flags %s must be set; got %s", describe(c.names), describeSet(set)),
+				})
+			}
+		case constraintMutuallyExclusive:
+			if len(set) > 1 {
+				errs = append(errs, &ConstraintError{
+					Names: set,
+					msg:   fmt.Sprintf("flags %s are mutually exclusive; got %s", describe(c.names), describeSet(set)),
+				})
+			}
+		case constraintRequiresIf:
+			name := c.names[0]
+			if !isSet(name) {
+				continue
+			}
+			var missing []string
+			for _, required := range c.names[1:] {
+				if !isSet(required) {
+					missing = append(missing, required)
+				}
+			}
+			if len(missing) > 0 {
+				errs = append(errs, &ConstraintError{
+					Names: append([]string{name}, missing...),
+					msg:   fmt.Sprintf("%s must be set when %s is set", describe(missing), describeSet([]string{name})),
+				})
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,102 @@
+package flagx
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestSetConstraints(t *testing.T) {
+	newSet := func() *Set {
+		s := NewSet("test", flag.ContinueOnError)
+		s.NewString("addr", "", "the address")
+		s.NewString("tls.cert", "", "the cert")
+		s.NewString("tls.key", "", "the key file")
+		s.NewBool("mode.a", false, "mode a")
+		s.NewBool("mode.b", false, "mode b")
+		s.NewBool("mode.c", false, "mode c")
+		s.NewString("output.file", "", "output file")
+		s.NewBool("output.stdout", false, "output to stdout")
+
+		s.Required("addr")
+		s.OneOf("mode.a", "mode.b", "mode.c")
+		s.MutuallyExclusive("output.file", "output.stdout")
+		s.RequiresIf("tls.cert", "tls.key")
+		return s
+	}
+
+	f := func(args []string, env map[string]string, expected []string) {
+		t.Helper()
+		for k, v := range env {
+			t.Setenv(k, v)
+		}
+		err := newSet().Parse(args)
+		var msgs []string
+		if err != nil {
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("unexpected error type %T: %s", err, err)
+			}
+			for _, ce := range pe.ConstraintErrors() {
+				msgs = append(msgs, ce.Error())
+			}
+		}
+		if !reflect.DeepEqual(msgs, expected) {
+			t.Fatalf("unexpected constraint errors for args=%q, env=%v;\ngot\n%q\nwant\n%q", args, env, msgs, expected)
+		}
+	}
+
+	// All the constraints are satisfied.
+	f([]string{"-addr=:80", "-mode.a"}, nil, nil)
+
+	// All the violations are reported at once.
+	f(nil, nil, []string{
+		"flag -addr is required",
+		"exactly one of flags -mode.a, -mode.b, -mode.c must be set; got none",
+	})
+	// Env vars are set for the rest of the test, so the cases with env vars go last.
+	f([]string{"-mode.b", "-tls.cert=cert.pem", "-tls.key=key.pem", "-output.file=out"}, map[string]string{"ADDR": ":80"}, nil)
+	f([]string{"-mode.a", "-mode.c", "-output.stdout", "-tls.cert=cert.pem"}, map[string]string{"OUTPUT_FILE": "out"}, []string{
+		"exactly one of flags -mode.a, -mode.b, -mode.c must be set; got -mode.a (from command line), -mode.c (from command line)",
+		"flags -output.file, -output.stdout are mutually exclusive; got -output.file (from env OUTPUT_FILE), -output.stdout (from command line)",
+		"-tls.key must be set when -tls.cert (from command line) is set",
+	})
+}
+
+func TestSetConstraintsUnknownFlag(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	s.NewString("addr", "", "the address")
+	s.Required("addr", "missing")
+	err := s.Parse([]string{"-addr=:80"})
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	expected := "cannot check constraint for unknown flags -missing"
+	if err.Error() != expected {
+		t.Fatalf("unexpected error; got %q; want %q", err, expected)
+	}
+}
+
+func TestParseFlagSetConstraints(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("constraint.test.name", "", "the name")
+	fs.String("constraint.test.other", "", "the other name")
+
+	saved := CommandLine.constraints
+	defer func() {
+		CommandLine.constraints = saved
+	}()
+	ForFlagSet(fs).Required("constraint.test.name")
+	// CommandLine constraints mustn't be checked for other flag sets.
+	MutuallyExclusive("constraint.test.name", "constraint.test.other")
+
+	err := ParseFlagSetE(fs, nil)
+	expected := "flag -constraint.test.name is required"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("unexpected error; got %v; want %q", err, expected)
+	}
+	if err := ParseFlagSetE(fs, []string{"-constraint.test.name=foo", "-constraint.test.other=bar"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,248 @@
+package flagx
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WriteMarkdown writes Markdown reference for all the flags registered at CommandLine to w.
+//
+// See Set.WriteMarkdown for details.
+func WriteMarkdown(w io.Writer, title string) {
+	CommandLine.WriteMarkdown(w, title)
+}
+
+// WriteManPage writes roff man page for all the flags registered at CommandLine to w.
+//
+// See Set.WriteManPage for details.
+func WriteManPage(w io.Writer, name, description string) {
+	CommandLine.WriteManPage(w, name, description)
+}
+
+// flagDoc describes a single flag in the generated reference.
+type flagDoc struct {
+	name         string
+	typ          string
+	defaultValue string
+	env          string
+	usage        string
+	secret       bool
+}
+
+// flagDocGroup contains flags sharing the first segment of dotted name.
+type flagDocGroup struct {
+	// prefix is the first segment of dotted flag names. It is empty for flags without dots.
+	prefix string
+	flags  []flagDoc
+}
+
+// flagDocGroups returns flags grouped by the first segment of their dotted names.
+//
+// Flags without dots go to the first group. The remaining groups are sorted by prefix.
+func (s *Set) flagDocGroups() []flagDocGroup {
+	m := make(map[string][]flagDoc)
+	s.fs.VisitAll(func(f *flag.Flag) {
+		_, usage := flag.UnquoteUsage(f)
+		fd := flagDoc{
+			name:         f.Name,
+			typ:          flagTypeName(f),
+			defaultValue: redactValue(f.Value, f.DefValue),
+			env:          s.getEnvFlagName(f.Name),
+			usage:        usage,
+			secret:       s.IsSecretFlag(f.Name),
+		}
+		if fd.secret && fd.defaultValue != "" {
+			fd.defaultValue = secretMarker
+		}
+		prefix, _, ok := strings.Cut(f.Name, ".")
+		if !ok {
+			prefix = ""
+		}
+		m[prefix] = append(m[prefix], fd)
+	})
+	groups := make([]flagDocGroup, 0, len(m))
+	for prefix, fds := range m {
+		groups = append(groups, flagDocGroup{
+			prefix: prefix,
+			flags:  fds,
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].prefix < groups[j].prefix
+	})
+	return groups
+}
+
+// flagTypeName returns human-readable type name for the value of f.
+func flagTypeName(f *flag.Flag) string {
+	switch unwrapValue(f.Value).(type) {
+	case *Bytes:
+		return "bytes"
+	case *Duration:
+		return "duration"
+	case *ArrayString:
+		return "array of strings"
+	case *ArrayBool:
+		return "array of bools"
+	case *ArrayInt:
+		return "array of ints"
+	case *ArrayDuration:
+		return "array of durations"
+	case *ArrayBytes:
+		return "array of bytes"
+	case *Enum:
+		return "enum"
+	case *ArrayEnum:
+		return "array of enums"
+	case *ArrayKV:
+		return "array of key-value pairs"
+	case *Map[string]:
+		return "map"
+	case *Map[time.Duration]:
+		return "map of durations"
+	case *Map[int64]:
+		return "map of bytes"
+	case *HostPort:
+		return "host:port"
+	case *IP:
+		return "ip"
+	case *CIDR:
+		return "cidr"
+	case *ArrayCIDR:
+		return "array of cidrs"
+	case *URL:
+		return "url"
+	case *Secret:
+		return "secret"
+	}
+	if a, ok := f.Value.(interface{ itemTypeName() string }); ok {
+		return "array of " + a.itemTypeName()
+	}
+	if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() {
+		return "bool"
+	}
+	if g, ok := f.Value.(flag.Getter); ok {
+		switch g.Get().(type) {
+		case string:
+			return "string"
+		case int:
+			return "int"
+		case int64:
+			return "int64"
+		case uint:
+			return "uint"
+		case uint64:
+			return "uint64"
+		case float64:
+			return "float"
+		}
+	}
+	return "value"
+}
+
+// WriteMarkdown writes Markdown reference for all the registered flags to w.
+//
+// Flags are grouped into tables by the first segment of their dotted names.
+// Every table row contains flag type, default value, environment var name and secret marker.
+// Default values of secret flags are redacted.
+func (s *Set) WriteMarkdown(w io.Writer, title string) {
+	if title != "" {
+		fmt.Fprintf(w, "# %s\n\n", markdownEscape(title))
+	}
+	for _, g := range s.flagDocGroups() {
+		if g.prefix == "" {
+			fmt.Fprintf(w, "## General flags\n\n")
+		} else {
+			fmt.Fprintf(w, "## %s\n\n", markdownEscape(g.prefix))
+		}
+		fmt.Fprintf(w, "| Flag | Type | Default | Env | Secret | Description |\n")
+		fmt.Fprintf(w, "|------|------|---------|-----|--------|-------------|\n")
+		for _, fd := range g.flags {
+			defaultValue := ""
+			if fd.defaultValue != "" {
+				defaultValue = markdownCode(fd.defaultValue)
+			}
+			secret := ""
+			if fd.secret {
+				secret = "yes"
+			}
+			fmt.Fprintf(w, "| `-%s` | %s | %s | `%s` | %s | %s |\n", fd.name, fd.typ, defaultValue, fd.env, secret, markdownEscape(fd.usage))
+		}
+		fmt.Fprintf(w, "\n")
+	}
+}
+
+var markdownReplacer = strings.NewReplacer(`\`, `\\`, `|`, `\|`, `*`, `\*`, `_`, `\_`, "`", "\\`", `<`, `&lt;`, `>`, `&gt;`, "\n", "<br>")
+
+func markdownEscape(s string) string {
+	return markdownReplacer.Replace(s)
+}
+
+// markdownCode returns s formatted as inline code suitable for table cells.
+func markdownCode(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\n", " ")
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
+
+// WriteManPage writes man page in roff format for all the registered flags to w.
+//
+// name is the program name and description is a short program description for the NAME section.
+// Flags are grouped into subsections by the first segment of their dotted names.
+// Default values of secret flags are redacted.
+func (s *Set) WriteManPage(w io.Writer, name, description string) {
+	fmt.Fprintf(w, ".TH %s 1\n", roffEscape(strings.ToUpper(name)))
+	fmt.Fprintf(w, ".SH NAME\n")
+	if description != "" {
+		fmt.Fprintf(w, "%s \\- %s\n", roffEscape(name), roffEscape(description))
+	} else {
+		fmt.Fprintf(w, "%s\n", roffEscape(name))
+	}
+	fmt.Fprintf(w, ".SH SYNOPSIS\n")
+	fmt.Fprintf(w, ".B %s\n", roffEscape(name))
+	fmt.Fprintf(w, "[\\fIflags\\fR]\n")
+	fmt.Fprintf(w, ".SH OPTIONS\n")
+	for _, g := range s.flagDocGroups() {
+		if g.prefix == "" {
+			fmt.Fprintf(w, ".SS General flags\n")
+		} else {
+			fmt.Fprintf(w, ".SS %s\n", roffEscape(g.prefix))
+		}
+		for _, fd := range g.flags {
+			fmt.Fprintf(w, ".TP\n")
+			fmt.Fprintf(w, ".BI \\-%s \" %s\"\n", roffEscape(fd.name), roffEscape(fd.typ))
+			for _, line := range strings.Split(fd.usage, "\n") {
+				fmt.Fprintf(w, "%s\n.br\n", roffEscape(line))
+			}
+			details := []string{"Env: " + fd.env}
+			if fd.defaultValue != "" {
+				details = append([]string{fmt.Sprintf("Default: %q", fd.defaultValue)}, details...)
+			}
+			if fd.secret {
+				details = append(details, "Secret")
+			}
+			fmt.Fprintf(w, "%s.\n", roffEscape(strings.Join(details, ". ")))
+		}
+	}
+	fmt.Fprintf(w, ".SH ENVIRONMENT\n")
+	fmt.Fprintf(w, "Every flag may be set via the environment variable listed in its description.\n")
+	fmt.Fprintf(w, "Command\\-line flags override environment variables, which override the config file passed via\n")
+	fmt.Fprintf(w, ".BR \\-config.file .\n")
+}
+
+// roffEscape escapes s for use in roff text lines.
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
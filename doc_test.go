@@ -0,0 +1,65 @@
+package flagx
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestSetWriteMarkdown(t *testing.T) {
+	s := NewSet("app", flag.ContinueOnError)
+	s.NewString("server.addr", ":80", "the `address` to listen | serve")
+	s.NewString("auth.password", "qwerty", "the password")
+	s.NewBytes("limit", 1024, "the limit")
+	s.NewArrayString("server.tags", "the tags")
+	s.NewBool("debug", false, ".dot at the start")
+	var bb bytes.Buffer
+	s.WriteMarkdown(&bb, "App flags")
+	result := bb.String()
+
+	f := func(expected string) {
+		t.Helper()
+		if !strings.Contains(result, expected) {
+			t.Fatalf("cannot find %q in markdown:\n%s", expected, result)
+		}
+	}
+	f("# App flags\n\n## General flags\n\n| Flag | Type | Default | Env | Secret | Description |\n|------|------|---------|-----|--------|-------------|\n" +
+		"| `-debug` | bool | `false` | `DEBUG` |  | .dot at the start |\n" +
+		"| `-limit` | bytes | `1024` | `LIMIT` |  | the limit<br>Supports the following optional suffixes for size values: KB, MB, GB, TB, KiB, MiB, GiB, TiB. |\n\n")
+	f("## auth\n\n| Flag | Type | Default | Env | Secret | Description |\n|------|------|---------|-----|--------|-------------|\n" +
+		"| `-auth.password` | string | `secret` | `AUTH_PASSWORD` | yes | the password |\n\n")
+	f("## server\n\n| Flag | Type | Default | Env | Secret | Description |\n|------|------|---------|-----|--------|-------------|\n" +
+		"| `-server.addr` | string | `:80` | `SERVER_ADDR` |  | the address to listen \\| serve |\n" +
+		"| `-server.tags` | array of strings |  | `SERVER_TAGS` |  | the tags<br>Supports an array of values separated by comma or specified via multiple flags. |\n\n")
+	if strings.Contains(result, "qwerty") {
+		t.Fatalf("secret default value must be redacted:\n%s", result)
+	}
+	if strings.Contains(result, "(env:") {
+		t.Fatalf("env hints must be stripped from descriptions:\n%s", result)
+	}
+}
+
+func TestSetWriteManPage(t *testing.T) {
+	s := NewSet("app", flag.ContinueOnError)
+	s.NewString("server.addr", ":80", "the `address` to listen | serve")
+	s.NewString("auth.password", "qwerty", "the password")
+	s.NewBool("debug", false, ".dot at the start")
+	var bb bytes.Buffer
+	s.WriteManPage(&bb, "app", "does things")
+	result := bb.String()
+
+	f := func(expected string) {
+		t.Helper()
+		if !strings.Contains(result, expected) {
+			t.Fatalf("cannot find %q in man page:\n%s", expected, result)
+		}
+	}
+	f(".TH APP 1\n.SH NAME\napp \\- does things\n")
+	f(".SS General flags\n.TP\n.BI \\-debug \" bool\"\n\\&.dot at the start\n.br\nDefault: \"false\". Env: DEBUG.\n")
+	f(".SS auth\n.TP\n.BI \\-auth.password \" string\"\nthe password\n.br\nDefault: \"secret\". Env: AUTH_PASSWORD. Secret.\n")
+	f(".SS server\n.TP\n.BI \\-server.addr \" string\"\nthe address to listen | serve\n.br\nDefault: \":80\". Env: SERVER_ADDR.\n")
+	if strings.Contains(result, "qwerty") {
+		t.Fatalf("secret default value must be redacted:\n%s", result)
+	}
+}
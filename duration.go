@@ -1,38 +1,91 @@
 package flagx
 
 import (
-	"flag"
 	"fmt"
 	"math"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 	"unicode"
 )
 
-// NewDuration returns new `duration` flag with the given name, defaultValue and description.
+// NewDuration returns new `duration` flag with the given name, defaultValue, description and optional opts.
 //
 // DefaultValue is in months.
-func NewDuration(name string, defaultValue string, description string) *Duration {
-	description += "\nThe following optional suffixes are supported: h (hour), d (day), w (week), m (month), y (year). If suffix isn't set, then the duration is counted in seconds." + envHelp(name)
-	d := &Duration{}
+func NewDuration(name string, defaultValue string, description string, opts ...Option) *Duration {
+	return CommandLine.NewDuration(name, defaultValue, description, opts...)
+}
+
+// NewDuration returns new `duration` flag with the given name, defaultValue, description and optional opts.
+//
+// DefaultValue is in months.
+//
+// AllowNegative, MinDuration, MaxDuration and Validate[time.Duration] options are supported.
+func (s *Set) NewDuration(name string, defaultValue string, description string, opts ...Option) *Duration {
+	o := applyOptions(opts)
+	validators, help := o.durationChecks(name)
+	description += durationHelp + help
+	d := &Duration{
+		allowNegative: o.allowNegative,
+		validators:    validators,
+	}
 	if err := d.Set(defaultValue); err != nil {
-		panic(fmt.Sprintf("BUG: can not parse default value %s for flag %s", defaultValue, name))
+		panic(fmt.Sprintf("BUG: invalid default value %q for flag %s: %s", defaultValue, name, err))
 	}
-	flag.Var(d, name, description)
+	s.fs.Var(d, name, description)
 	return d
 }
 
+const durationHelp = "\nThe following optional suffixes are supported: h (hour), d (day), w (week), m (month), y (year). If suffix isn't set, then the duration is counted in seconds."
+
 // Duration is a flag for holding duration.
+//
+// Use Load for reading the value from goroutines running concurrently with Set, i.e. during Reload.
 type Duration struct {
 	// Msecs contains parsed duration in milliseconds.
+	//
+	// Msecs is updated by Set without synchronization, so it mustn't be read while Set or Reload may run
+	// in another goroutine.
+	//
+	// Deprecated: use Load instead.
 	Msecs int64
 
+	state atomic.Pointer[durationState]
+
+	allowNegative bool
+	validators    []func(d time.Duration) error
+}
+
+type durationState struct {
+	msecs       int64
 	valueString string
 }
 
+// Load returns the stored duration.
+//
+// It is safe calling Load concurrently with Set.
+func (d *Duration) Load() time.Duration {
+	if st := d.state.Load(); st != nil {
+		return time.Duration(st.msecs) * time.Millisecond
+	}
+	return time.Duration(d.Msecs) * time.Millisecond
+}
+
 // String implements flag.Value interface
 func (d *Duration) String() string {
-	return d.valueString
+	if st := d.state.Load(); st != nil {
+		return st.valueString
+	}
+	return ""
+}
+
+func (d *Duration) store(msecs int64, valueString string) {
+	d.Msecs = msecs
+	d.state.Store(&durationState{
+		msecs:       msecs,
+		valueString: valueString,
+	})
 }
 
 // Set implements flag.Value interface
@@ -40,24 +93,54 @@ func (d *Duration) Set(value string) error {
 	// An attempt to parse value in seconds.
 	seconds, err := strconv.ParseFloat(value, 64)
 	if err == nil {
-		if seconds < 0 {
+		if seconds < 0 && !d.allowNegative {
 			return fmt.Errorf("duration seconds cannot be negative; got %g", seconds)
 		}
-		d.Msecs = int64(seconds * 1000)
-		d.valueString = value
-		return nil
+		return d.validateAndStore(int64(seconds*1000), value)
 	}
 	// Parse duration.
 	value = strings.ToLower(value)
-	msecs, err := PositiveDurationValue(value, 0)
+	var msecs int64
+	if d.allowNegative {
+		msecs, err = DurationValue(value, 0)
+	} else {
+		msecs, err = PositiveDurationValue(value, 0)
+	}
 	if err != nil {
 		return err
 	}
-	d.Msecs = msecs
-	d.valueString = value
+	return d.validateAndStore(msecs, value)
+}
+
+func (d *Duration) validateAndStore(msecs int64, valueString string) error {
+	for _, v := range d.validators {
+		if err := v(time.Duration(msecs) * time.Millisecond); err != nil {
+			return err
+		}
+	}
+	d.store(msecs, valueString)
 	return nil
 }
 
+// parseDuration parses s the same way as Duration.Set.
+//
+// Values supported by time.ParseDuration are accepted too, so sub-millisecond durations such as `1.5ms` keep their precision.
+func parseDuration(s string, allowNegative bool) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 && !allowNegative {
+			return 0, fmt.Errorf("duration cannot be negative; got %q", s)
+		}
+		return d, nil
+	}
+	d := Duration{
+		allowNegative: allowNegative,
+	}
+	if err := d.Set(s); err != nil {
+		return 0, err
+	}
+	return d.Load(), nil
+}
+
 // PositiveDurationValue returns positive duration in milliseconds for the given s
 // and the given step.
 //
@@ -1,8 +1,10 @@
 package flagx
 
 import (
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestDurationSetFailure(t *testing.T) {
@@ -41,8 +43,8 @@ func TestDurationSetSuccess(t *testing.T) {
 		if err := d.Set(value); err != nil {
 			t.Fatalf("unexpected error in d.Set(%q): %s", value, err)
 		}
-		if d.Msecs != expectedMsecs {
-			t.Fatalf("unexpected result; got %d; want %d", d.Msecs, expectedMsecs)
+		if d.Load().Milliseconds() != expectedMsecs {
+			t.Fatalf("unexpected result; got %d; want %d", d.Load().Milliseconds(), expectedMsecs)
 		}
 		valueString := d.String()
 		valueExpected := strings.ToLower(value)
@@ -57,3 +59,32 @@ func TestDurationSetSuccess(t *testing.T) {
 	f("1w", 7*24*3600*1000)
 	f("0.25y", 0.25*365*24*3600*1000)
 }
+
+func TestDurationConcurrentLoad(t *testing.T) {
+	var d Duration
+	if err := d.Set("1h"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			if v := d.Load(); v != time.Hour && v != 24*time.Hour {
+				panic(fmt.Errorf("unexpected value loaded: %s", v))
+			}
+			if s := d.String(); s != "1h" && s != "1d" {
+				panic(fmt.Errorf("unexpected string loaded: %q", s))
+			}
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		v := "1h"
+		if i%2 == 0 {
+			v = "1d"
+		}
+		if err := d.Set(v); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	<-done
+}
@@ -0,0 +1,193 @@
+package flagx
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// encryptedValuePrefix is the prefix for flag values encrypted via EncryptValue.
+const encryptedValuePrefix = "enc:"
+
+// defaultEncryptionKeyEnv is the env var with the encryption key used unless SetEncryptionKeyEnv is called.
+const defaultEncryptionKeyEnv = "FLAGX_ENCRYPTION_KEY"
+
+// SetEncryptionKeyFile sets the path to the file with the key for decrypting `enc:` values at CommandLine.
+//
+// See Set.SetEncryptionKeyFile for details.
+func SetEncryptionKeyFile(path string) {
+	CommandLine.SetEncryptionKeyFile(path)
+}
+
+// SetEncryptionKeyEnv sets the name of env var with the key for decrypting `enc:` values at CommandLine.
+//
+// See Set.SetEncryptionKeyEnv for details.
+func SetEncryptionKeyEnv(name string) {
+	CommandLine.SetEncryptionKeyEnv(name)
+}
+
+// EncryptValue encrypts value with the key configured at CommandLine.
+//
+// See Set.EncryptValue for details.
+func EncryptValue(value string) (string, error) {
+	return CommandLine.EncryptValue(value)
+}
+
+// SetEncryptionKeyFile sets the path to the file with the key for decrypting `enc:` flag values.
+//
+// Flag values in `enc:...` form obtained from any source, including command line, are decrypted at Parse* time
+// and on Reload. The file must contain base64-encoded 16, 24 or 32 byte key for AES-GCM.
+// Flags with decrypted values are recognized as secret by IsSecretFlag.
+//
+// The key is read from the env var set via SetEncryptionKeyEnv if the path is empty.
+// The key is read only if there are `enc:` values, so it isn't required otherwise.
+func (s *Set) SetEncryptionKeyFile(path string) {
+	s.encryptionKeyFile = path
+}
+
+// SetEncryptionKeyEnv sets the name of env var with the key for decrypting `enc:` flag values.
+//
+// The env var must contain base64-encoded key. It is used only if the key file isn't set via SetEncryptionKeyFile.
+// The default env var is FLAGX_ENCRYPTION_KEY.
+func (s *Set) SetEncryptionKeyEnv(name string) {
+	s.encryptionKeyEnv = name
+}
+
+// EncryptValue encrypts value with the key set via SetEncryptionKeyFile or SetEncryptionKeyEnv.
+//
+// The returned `enc:...` value may be passed to the flag via any source.
+// See also the `cmd/flagx-encrypt` command.
+func (s *Set) EncryptValue(value string) (string, error) {
+	key, err := s.loadEncryptionKey()
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("cannot generate nonce: %w", err)
+	}
+	data := aead.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedValuePrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// encryptedValues holds the state for decrypting `enc:` values for Set.
+type encryptedValues struct {
+	mu sync.Mutex
+
+	// key is the loaded encryption key. It is reset on Reload, so the rotated key is picked up.
+	key []byte
+
+	// flags contains names of flags with decrypted values.
+	flags map[string]bool
+}
+
+// decryptValue returns the decrypted value for the flag with the given name and `enc:` value.
+func (s *Set) decryptValue(name, value string) (string, error) {
+	ev := s.encrypted
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+	if ev.key == nil {
+		key, err := s.loadEncryptionKey()
+		if err != nil {
+			return "", err
+		}
+		ev.key = key
+	}
+	v, err := decryptValue(ev.key, value)
+	if err != nil {
+		return "", err
+	}
+	if ev.flags == nil {
+		ev.flags = make(map[string]bool)
+	}
+	ev.flags[name] = true
+	return v, nil
+}
+
+// decryptValue decrypts `enc:` value with the given key.
+//
+// The returned error never contains the value.
+func decryptValue(key []byte, value string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedValuePrefix))
+	if err != nil {
+		return "", fmt.Errorf("cannot decode encrypted value: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < aead.NonceSize() {
+		return "", fmt.Errorf("cannot decrypt value: too short data")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt value; make sure it is encrypted with the same key: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// loadEncryptionKey reads the key from the file set via SetEncryptionKeyFile or from the env var set via SetEncryptionKeyEnv.
+func (s *Set) loadEncryptionKey() ([]byte, error) {
+	var data string
+	if path := s.encryptionKeyFile; path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read encryption key file: %w", err)
+		}
+		data = string(b)
+	} else {
+		name := s.encryptionKeyEnv
+		if name == "" {
+			name = defaultEncryptionKeyEnv
+		}
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("missing encryption key; set it via %s env var or SetEncryptionKeyFile", name)
+		}
+		data = v
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode base64-encoded encryption key: %w", err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("invalid encryption key size: %d bytes; it must be 16, 24 or 32 bytes", len(key))
+	}
+}
+
+// isDecryptedFlag returns true if the flag with the given name has a value decrypted from `enc:` value.
+func (s *Set) isDecryptedFlag(name string) bool {
+	ev := s.encrypted
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+	return ev.flags[name]
+}
+
+// resetEncryptionKey drops the loaded key, so it is loaded again.
+func (s *Set) resetEncryptionKey() {
+	ev := s.encrypted
+	ev.mu.Lock()
+	ev.key = nil
+	ev.mu.Unlock()
+}
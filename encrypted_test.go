@@ -0,0 +1,122 @@
+package flagx
+
+import (
+	"encoding/base64"
+	"flag"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+const testEncryptionKey = "hNBunROpuyQA+8Qt1+ONfPs9IRQXorRp89zA18aK9i8="
+
+func TestEncryptedValues(t *testing.T) {
+	keyPath := writeTestFile(t, "flagx.key", testEncryptionKey+"\n")
+	s := NewSet("test", flag.ContinueOnError)
+	s.SetEncryptionKeyFile(keyPath)
+	encrypted, err := s.EncryptValue("hunter2")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.HasPrefix(encrypted, "enc:") || strings.Contains(encrypted, "hunter2") {
+		t.Fatalf("unexpected encrypted value: %q", encrypted)
+	}
+
+	path := writeTestFile(t, "config.yaml", "api:\n  auth: "+encrypted+"\n")
+	dbAuth := s.NewString("db.auth", "", "")
+	apiAuth := s.NewString("api.auth", "", "")
+	s.NewString("plain", "", "")
+	if err := s.ParseWithFile([]string{"-db.auth=" + encrypted, "-plain=foo"}, path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f := func(name string, result *string) {
+		t.Helper()
+		if *result != "hunter2" {
+			t.Fatalf("unexpected value for %s; got %q; want %q", name, *result, "hunter2")
+		}
+		if reason := s.secretReason(name); reason != "value is decrypted from enc: value" {
+			t.Fatalf("unexpected secret reason for %s: %q", name, reason)
+		}
+	}
+	f("db.auth", dbAuth)
+	f("api.auth", apiAuth)
+	if s.IsSecretFlag("plain") {
+		t.Fatalf("flag plain mustn't be secret")
+	}
+}
+
+func TestEncryptedValuesFailure(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	s.SetEncryptionKeyFile(writeTestFile(t, "flagx.key", testEncryptionKey))
+	encrypted, err := s.EncryptValue("hunter2")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	f := func(key, value, expected string) {
+		t.Helper()
+		s := NewSet("test", flag.ContinueOnError)
+		s.SetEncryptionKeyEnv("TEST_FLAGX_ENCRYPTION_KEY")
+		s.NewString("value", "", "")
+		s.FlagSet().SetOutput(io.Discard)
+		if key != "" {
+			t.Setenv("TEST_FLAGX_ENCRYPTION_KEY", key)
+		}
+		err := s.Parse([]string{"-value=" + value})
+		if err == nil {
+			t.Fatalf("expecting non-nil error for %q", value)
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("unexpected error for %q; got %q; want it to contain %q", value, err, expected)
+		}
+		if strings.Contains(err.Error(), "hunter2") {
+			t.Fatalf("the error mustn't contain the decrypted value: %q", err)
+		}
+	}
+	otherKey := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	f("", encrypted, "missing encryption key; set it via TEST_FLAGX_ENCRYPTION_KEY env var")
+	f("Zm9v", encrypted, "invalid encryption key size: 3 bytes")
+	f("!", encrypted, "cannot decode base64-encoded encryption key")
+	f(otherKey, encrypted, "make sure it is encrypted with the same key")
+	f(testEncryptionKey, "enc:!", "cannot decode encrypted value")
+	f(testEncryptionKey, "enc:", "too short data")
+}
+
+func TestEncryptedValuesReload(t *testing.T) {
+	keyPath := writeTestFile(t, "flagx.key", testEncryptionKey)
+	s := NewSet("test", flag.ContinueOnError)
+	s.SetEncryptionKeyFile(keyPath)
+	first, err := s.EncryptValue("first")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	path := writeTestFile(t, "config.yaml", "api:\n  auth: "+first+"\n")
+	apiAuth := s.NewString("api.auth", "", "")
+	s.MarkReloadable("api.auth")
+	if err := s.ParseWithFile(nil, path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if *apiAuth != "first" {
+		t.Fatalf("unexpected value; got %q; want %q", *apiAuth, "first")
+	}
+
+	// The rotated key must be picked up on reload.
+	rotatedKey := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef"))
+	if err := os.WriteFile(keyPath, []byte(rotatedKey), 0o600); err != nil {
+		t.Fatalf("cannot update key file: %s", err)
+	}
+	second, err := s.EncryptValue("second")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := os.WriteFile(path, []byte("api:\n  auth: "+second+"\n"), 0o600); err != nil {
+		t.Fatalf("cannot update config file: %s", err)
+	}
+	if err := s.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if *apiAuth != "second" {
+		t.Fatalf("unexpected value after reload; got %q; want %q", *apiAuth, "second")
+	}
+}
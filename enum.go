@@ -0,0 +1,221 @@
+package flagx
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// NewEnum returns new `enum` flag with the given name, defaultValue, choices and description.
+func NewEnum(name, defaultValue string, choices []string, description string) *Enum {
+	return CommandLine.NewEnum(name, defaultValue, choices, description)
+}
+
+// NewEnum returns new `enum` flag with the given name, defaultValue, choices and description.
+//
+// The flag accepts only values from choices. The values are matched case-insensitively.
+// defaultValue must be one of choices or empty. An empty defaultValue leaves the flag unset,
+// and the flag may be reset to the empty value then.
+// The choices are listed in the flag description, shell completion and generated docs.
+func (s *Set) NewEnum(name, defaultValue string, choices []string, description string) *Enum {
+	description += enumHelp(choices)
+	e := &Enum{
+		choices:    choices,
+		allowEmpty: defaultValue == "",
+	}
+	if err := e.Set(defaultValue); err != nil {
+		panic(fmt.Sprintf("BUG: invalid default value %q for flag %s: %s", defaultValue, name, err))
+	}
+	s.fs.Var(e, name, description)
+	return e
+}
+
+// NewArrayEnum returns new ArrayEnum with the given name, choices and description.
+func NewArrayEnum(name string, choices []string, description string) *ArrayEnum {
+	return CommandLine.NewArrayEnum(name, choices, description)
+}
+
+// NewArrayEnum returns new ArrayEnum with the given name, choices and description.
+//
+// See NewEnum for details on choices.
+func (s *Set) NewArrayEnum(name string, choices []string, description string) *ArrayEnum {
+	description += arrayHelp + enumHelp(choices)
+	a := &ArrayEnum{
+		choices: choices,
+	}
+	s.fs.Var(a, name, description)
+	return a
+}
+
+func enumHelp(choices []string) string {
+	return fmt.Sprintf("\nSupported values: %s.", strings.Join(choices, ", "))
+}
+
+// Enum is a flag for holding a value from a fixed set of choices.
+//
+// Use Load for reading the value from goroutines running concurrently with Set, i.e. during Reload.
+type Enum struct {
+	choices []string
+
+	// allowEmpty is set to true if the default value is empty, so the flag may be reset to it.
+	allowEmpty bool
+
+	value atomic.Pointer[string]
+}
+
+// Load returns the stored value.
+//
+// It is safe calling Load concurrently with Set.
+func (e *Enum) Load() string {
+	if v := e.value.Load(); v != nil {
+		return *v
+	}
+	return ""
+}
+
+// String implements flag.Value interface
+func (e *Enum) String() string {
+	return e.Load()
+}
+
+// Set implements flag.Value interface
+//
+// The value is matched against choices case-insensitively, and the matching choice is stored.
+func (e *Enum) Set(value string) error {
+	if value == "" && e.allowEmpty {
+		e.value.Store(&value)
+		return nil
+	}
+	v, err := matchChoice(e.choices, value)
+	if err != nil {
+		return err
+	}
+	e.value.Store(&v)
+	return nil
+}
+
+// Choices returns the supported values.
+func (e *Enum) Choices() []string {
+	return append([]string(nil), e.choices...)
+}
+
+// ArrayEnum is a flag that holds an array of values from a fixed set of choices.
+//
+// It may be set the same way as ArrayString.
+// Use Snapshot for reading the values from goroutines running concurrently with Reload.
+type ArrayEnum struct {
+	choices []string
+
+	a ArrayString
+}
+
+// Snapshot returns a copy of the stored values.
+//
+// It is safe calling Snapshot concurrently with Set and Reload.
+func (a *ArrayEnum) Snapshot() []string {
+	return a.a.Snapshot()
+}
+
+// String implements flag.Value interface
+func (a *ArrayEnum) String() string {
+	return a.a.String()
+}
+
+// Set implements flag.Value interface
+//
+// Every value is matched against choices case-insensitively, and the matching choices are stored.
+func (a *ArrayEnum) Set(value string) error {
+	values, err := a.parse(value)
+	if err != nil {
+		return err
+	}
+	a.a.add(values)
+	return nil
+}
+
+func (a *ArrayEnum) replace(value string) error {
+	values, err := a.parse(value)
+	if err != nil {
+		return err
+	}
+	a.a.store(values)
+	return nil
+}
+
+func (a *ArrayEnum) parse(value string) ([]string, error) {
+	values := parseArrayValues(value)
+	for i, v := range values {
+		choice, err := matchChoice(a.choices, v)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = choice
+	}
+	return values, nil
+}
+
+// Choices returns the supported values.
+func (a *ArrayEnum) Choices() []string {
+	return append([]string(nil), a.choices...)
+}
+
+// matchChoice returns the choice matching value case-insensitively.
+//
+// The returned error contains the closest choice if value looks like a typo.
+func matchChoice(choices []string, value string) (string, error) {
+	for _, choice := range choices {
+		if strings.EqualFold(choice, value) {
+			return choice, nil
+		}
+	}
+	err := fmt.Errorf("unsupported value %q; supported values: %s", value, strings.Join(choices, ", "))
+	if suggestion := suggestChoice(choices, value); suggestion != "" {
+		err = fmt.Errorf("%w; did you mean %q?", err, suggestion)
+	}
+	return "", err
+}
+
+// suggestChoice returns the choice closest to value or an empty string if there are no close choices.
+func suggestChoice(choices []string, value string) string {
+	value = strings.ToLower(value)
+	best := ""
+	bestDistance := 0
+	for _, choice := range choices {
+		lc := strings.ToLower(choice)
+		d := editDistance(lc, value)
+		if value != "" && strings.HasPrefix(lc, value) {
+			// Treat prefixes as close matches, i.e. `warn` for `warning`.
+			d = min(d, 1)
+		}
+		if best == "" || d < bestDistance {
+			best = choice
+			bestDistance = d
+		}
+	}
+	// Do not suggest choices, which need too many edits.
+	if best == "" || bestDistance > 2 || bestDistance >= len(value) {
+		return ""
+	}
+	return best
+}
+
+// editDistance returns Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,133 @@
+package flagx
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnumSetSuccess(t *testing.T) {
+	f := func(value, expected string) {
+		t.Helper()
+		e := &Enum{
+			choices: []string{"debug", "info", "WARN"},
+		}
+		if err := e.Set(value); err != nil {
+			t.Fatalf("unexpected error in e.Set(%q): %s", value, err)
+		}
+		if result := e.Load(); result != expected {
+			t.Fatalf("unexpected value; got %q; want %q", result, expected)
+		}
+		if result := e.String(); result != expected {
+			t.Fatalf("unexpected String(); got %q; want %q", result, expected)
+		}
+	}
+	f("debug", "debug")
+	f("INFO", "info")
+	f("warn", "WARN")
+}
+
+func TestEnumSetFailure(t *testing.T) {
+	f := func(value, expected string) {
+		t.Helper()
+		e := &Enum{
+			choices: []string{"debug", "info", "warning", "error"},
+		}
+		err := e.Set(value)
+		if err == nil {
+			t.Fatalf("expecting non-nil error in e.Set(%q)", value)
+		}
+		if err.Error() != expected {
+			t.Fatalf("unexpected error; got %q; want %q", err, expected)
+		}
+	}
+	f("", `unsupported value ""; supported values: debug, info, warning, error`)
+	f("fatal", `unsupported value "fatal"; supported values: debug, info, warning, error`)
+	f("ifno", `unsupported value "ifno"; supported values: debug, info, warning, error; did you mean "info"?`)
+	f("warn", `unsupported value "warn"; supported values: debug, info, warning, error; did you mean "warning"?`)
+	f("errors", `unsupported value "errors"; supported values: debug, info, warning, error; did you mean "error"?`)
+}
+
+func TestSetNewEnum(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	level := s.NewEnum("log.level", "info", []string{"debug", "info", "error"}, "the log level")
+	codecs := s.NewArrayEnum("codecs", []string{"gzip", "zstd", "snappy"}, "the codecs")
+
+	if level.Load() != "info" {
+		t.Fatalf("unexpected default value; got %q; want %q", level.Load(), "info")
+	}
+	usage := s.FlagSet().Lookup("log.level").Usage
+	if !strings.HasSuffix(usage, "\nSupported values: debug, info, error.") {
+		t.Fatalf("cannot find choices in usage %q", usage)
+	}
+	if err := s.Parse([]string{"-log.level=ERROR", "-codecs=GZIP,zstd", "-codecs=snappy"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if level.Load() != "error" {
+		t.Fatalf("unexpected value; got %q; want %q", level.Load(), "error")
+	}
+	expected := []string{"gzip", "zstd", "snappy"}
+	if result := codecs.Snapshot(); !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected codecs; got %q; want %q", result, expected)
+	}
+	if err := codecs.Set("lz4"); err == nil {
+		t.Fatalf("expecting non-nil error for unsupported value")
+	}
+	if result := codecs.Snapshot(); !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected codecs after the rejected Set; got %q; want %q", result, expected)
+	}
+}
+
+func TestSetNewEnumInvalidDefault(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expecting panic")
+		}
+	}()
+	NewSet("test", flag.ContinueOnError).NewEnum("mode", "foo", []string{"a", "b"}, "")
+}
+
+func TestSetNewEnumEmptyDefault(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	mode := s.NewEnum("mode", "", []string{"a", "b"}, "")
+	if result := mode.Load(); result != "" {
+		t.Fatalf("unexpected default value; got %q; want empty value", result)
+	}
+	if err := s.Parse([]string{"-mode=B"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result := mode.Load(); result != "b" {
+		t.Fatalf("unexpected value; got %q; want %q", result, "b")
+	}
+	if err := mode.Set(""); err != nil {
+		t.Fatalf("unexpected error when resetting the value: %s", err)
+	}
+	if err := mode.Set("c"); err == nil {
+		t.Fatalf("expecting non-nil error for unsupported value")
+	}
+}
+
+func TestEnumCompletion(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	s.NewEnum("log.level", "info", []string{"debug", "info"}, "the log level")
+	var sb strings.Builder
+	if err := s.WriteCompletion(&sb, "fish", "app"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := `complete -c 'app' -o 'log.level' -d 'the log level' -r -f -a 'debug info'`
+	if !strings.Contains(sb.String(), expected) {
+		t.Fatalf("cannot find %q in completion:\n%s", expected, sb.String())
+	}
+}
+
+func TestEnumMarkdown(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	s.NewEnum("log.level", "info", []string{"debug", "info"}, "the log level")
+	var sb strings.Builder
+	s.WriteMarkdown(&sb, "")
+	expected := "| `-log.level` | enum | `info` | `LOG_LEVEL` |  | the log level<br>Supported values: debug, info. |"
+	if !strings.Contains(sb.String(), expected) {
+		t.Fatalf("cannot find %q in markdown:\n%s", expected, sb.String())
+	}
+}
@@ -0,0 +1,94 @@
+package flagx
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// FlagError is returned when the flag cannot be set to the value read from some source.
+type FlagError struct {
+	// Name is the flag name.
+	Name string
+
+	// Source describes where the value is read from, i.e. `env SERVER_ADDR` or `file config.yaml:12`.
+	Source string
+
+	// Value is the raw value, which has been rejected by the flag.
+	Value string
+
+	// Err is the underlying error returned from flag.Value.Set.
+	Err error
+
+	// secret is set to true if the flag is registered as secret.
+	secret bool
+
+	// value is the flag value, which is used for redacting passwords from urls in Value.
+	value flag.Value
+}
+
+// Error implements error interface.
+//
+// The value isn't included in the error message for secret flags and for values, which look like secrets,
+// while passwords are redacted from values of url flags. See Set.Secrets for details.
+func (e *FlagError) Error() string {
+	value := redactValue(e.value, e.Value)
+	if e.secret || IsSecretFlag(e.Name) || secretValueReason(e.value, e.Value) != "" {
+		value = secretMarker
+	}
+	return fmt.Sprintf("cannot set flag %s to %q, which is read from %s: %s", e.Name, value, e.Source, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FlagError) Unwrap() error {
+	return e.Err
+}
+
+// ParseError holds all the errors occurred during flags parsing.
+type ParseError struct {
+	// Errors contains the occurred errors in the order they were detected.
+	//
+	// Errors for individual flag values have *FlagError type.
+	// Violated constraints have *ConstraintError type.
+	Errors []error
+}
+
+// Error implements error interface.
+func (e *ParseError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d errors occurred during flags parsing:", len(e.Errors))
+	for _, err := range e.Errors {
+		fmt.Fprintf(&sb, "\n\t%s", err)
+	}
+	return sb.String()
+}
+
+// Unwrap returns all the occurred errors, so they may be inspected with errors.Is and errors.As.
+func (e *ParseError) Unwrap() []error {
+	return e.Errors
+}
+
+// FlagErrors returns errors for individual flag values.
+func (e *ParseError) FlagErrors() []*FlagError {
+	var a []*FlagError
+	for _, err := range e.Errors {
+		if fe, ok := err.(*FlagError); ok {
+			a = append(a, fe)
+		}
+	}
+	return a
+}
+
+// ConstraintErrors returns errors for violated flag constraints.
+func (e *ParseError) ConstraintErrors() []*ConstraintError {
+	var a []*ConstraintError
+	for _, err := range e.Errors {
+		if ce, ok := err.(*ConstraintError); ok {
+			a = append(a, ce)
+		}
+	}
+	return a
+}
@@ -0,0 +1,286 @@
+package flagx
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// fileValue is a flag value read from config file.
+type fileValue struct {
+	// value is the raw flag value. Sequence items are joined by comma.
+	value string
+	// line is the line number in the config file, where the value is defined.
+	line int
+	// isArray is set to true if the value is defined as a sequence.
+	isArray bool
+	// isNull is set to true if the value is empty, such as a section with all the keys commented out.
+	isNull bool
+}
+
+// readConfigFile reads flag values from the config file at path.
+//
+// The file format is detected by the file extension: .toml files are parsed as TOML,
+// .json files are parsed as JSON and the rest of files are parsed as YAML.
+//
+// The returned map is keyed by dotted flag names.
+func readConfigFile(path string) (map[string]*fileValue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config file: %w", err)
+	}
+	values := make(map[string]*fileValue)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = flattenTOML(values, data)
+	case ".json":
+		err = flattenJSON(values, data)
+	default:
+		err = flattenYAML(values, data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse config file %q: %w", path, err)
+	}
+	return values, nil
+}
+
+func flattenYAML(dst map[string]*fileValue, data []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		// Empty file.
+		return nil
+	}
+	return flattenYAMLNode(dst, "", doc.Content[0])
+}
+
+func flattenYAMLNode(dst map[string]*fileValue, name string, n *yaml.Node) error {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: unsupported non-scalar key", k.Line)
+			}
+			key := k.Value
+			if name != "" {
+				key = name + "." + key
+			}
+			if fv, ok := dst[key]; ok {
+				return fmt.Errorf("line %d: duplicate key %q; previously defined at line %d", k.Line, key, fv.line)
+			}
+			if err := flattenYAMLNode(dst, key, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	case yaml.SequenceNode:
+		if name == "" {
+			return fmt.Errorf("line %d: the top-level node must be a mapping", n.Line)
+		}
+		items := make([]string, 0, len(n.Content))
+		for _, item := range n.Content {
+			if item.Kind == yaml.AliasNode {
+				item = item.Alias
+			}
+			if item.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: unsupported nested value in sequence %q", item.Line, name)
+			}
+			items = append(items, item.Value)
+		}
+		dst[name] = &fileValue{
+			value:   formatArrayValues(items),
+			line:    n.Line,
+			isArray: true,
+		}
+		return nil
+	case yaml.ScalarNode:
+		if name == "" {
+			return fmt.Errorf("line %d: the top-level node must be a mapping", n.Line)
+		}
+		isNull := n.Tag == "!!null"
+		value := n.Value
+		if isNull {
+			value = ""
+		}
+		dst[name] = &fileValue{
+			value:  value,
+			line:   n.Line,
+			isNull: isNull,
+		}
+		return nil
+	default:
+		return fmt.Errorf("line %d: unsupported yaml node", n.Line)
+	}
+}
+
+// flattenTOML reads flag values from TOML data into dst.
+//
+// TOML parser doesn't expose key positions, so line numbers aren't tracked for TOML files.
+func flattenTOML(dst map[string]*fileValue, data []byte) error {
+	var m map[string]any
+	if _, err := toml.Decode(string(data), &m); err != nil {
+		return err
+	}
+	return flattenTOMLTable(dst, "", m)
+}
+
+func flattenTOMLTable(dst map[string]*fileValue, prefix string, m map[string]any) error {
+	for k, v := range m {
+		name := k
+		if prefix != "" {
+			name = prefix + "." + k
+		}
+		if _, ok := dst[name]; ok {
+			return fmt.Errorf("duplicate key %q", name)
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			if err := flattenTOMLTable(dst, name, v); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := formatScalar(item)
+				if !ok {
+					return fmt.Errorf("unsupported nested value in array %q", name)
+				}
+				items = append(items, s)
+			}
+			dst[name] = &fileValue{
+				value:   formatArrayValues(items),
+				isArray: true,
+			}
+		default:
+			s, ok := formatScalar(v)
+			if !ok {
+				return fmt.Errorf("unsupported value for %q", name)
+			}
+			dst[name] = &fileValue{
+				value: s,
+			}
+		}
+	}
+	return nil
+}
+
+// flattenJSON reads flag values from JSON data into dst.
+func flattenJSON(dst map[string]*fileValue, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			// Empty file.
+			return nil
+		}
+		return err
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf("the top-level value must be an object")
+	}
+	lineAt := func(offset int64) int {
+		return 1 + bytes.Count(data[:offset], []byte("\n"))
+	}
+	return flattenJSONObject(dst, "", dec, lineAt)
+}
+
+func flattenJSONObject(dst map[string]*fileValue, prefix string, dec *json.Decoder, lineAt func(int64) int) error {
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		line := lineAt(dec.InputOffset())
+		name := tok.(string)
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if fv, ok := dst[name]; ok {
+			return fmt.Errorf("line %d: duplicate key %q; previously defined at line %d", line, name, fv.line)
+		}
+		tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			if err := flattenJSONObject(dst, name, dec, lineAt); err != nil {
+				return err
+			}
+		case json.Delim('['):
+			var items []string
+			for dec.More() {
+				tok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				s, ok := formatScalar(tok)
+				if !ok {
+					return fmt.Errorf("line %d: unsupported nested value in array %q", lineAt(dec.InputOffset()), name)
+				}
+				items = append(items, s)
+			}
+			if _, err := dec.Token(); err != nil {
+				return err
+			}
+			dst[name] = &fileValue{
+				value:   formatArrayValues(items),
+				line:    line,
+				isArray: true,
+			}
+		default:
+			s, _ := formatScalar(tok)
+			dst[name] = &fileValue{
+				value: s,
+				line:  line,
+			}
+		}
+	}
+	// Consume the closing brace.
+	_, err := dec.Token()
+	return err
+}
+
+// formatScalar returns the flag value representation for scalar v decoded from config file.
+func formatScalar(v any) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case json.Number:
+		return v.String(), true
+	case time.Time:
+		return v.Format(time.RFC3339Nano), true
+	}
+	return "", false
+}
+
+// isArrayValue returns true if v accumulates values on Set calls, so it may be defined as a sequence in config file.
+func isArrayValue(v flag.Value) bool {
+	_, ok := v.(replacer)
+	return ok
+}
@@ -0,0 +1,197 @@
+package flagx
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("cannot write test file: %s", err)
+	}
+	return path
+}
+
+func TestParseFlagSetWithFile(t *testing.T) {
+	path := writeTestFile(t, "config.yaml", `
+name: foo
+server:
+  addr: ":80"
+  port: 8080
+  timeout: 1d
+log:
+  level: debug
+array:
+  str: [a, "b,c"]
+  int:
+    - 1
+    - 2
+`)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	name := fs.String("name", "", "")
+	addr := fs.String("server.addr", "", "")
+	port := fs.Int("server.port", 0, "")
+	timeout := &Duration{}
+	fs.Var(timeout, "server.timeout", "")
+	level := fs.String("log.level", "info", "")
+	var strs ArrayString
+	fs.Var(&strs, "array.str", "")
+	var ints ArrayInt
+	fs.Var(&ints, "array.int", "")
+
+	t.Setenv("SERVER_PORT", "9090")
+	ParseFlagSetWithFile(fs, []string{"-log.level=warn"}, path)
+
+	if *name != "foo" || *addr != ":80" {
+		t.Fatalf("unexpected values read from file: %q, %q", *name, *addr)
+	}
+	if *port != 9090 {
+		t.Fatalf("env var must override config file; got %d; want %d", *port, 9090)
+	}
+	if *level != "warn" {
+		t.Fatalf("command-line flag must override config file; got %q; want %q", *level, "warn")
+	}
+	if timeout.Load().Milliseconds() != 24*3600*1000 {
+		t.Fatalf("unexpected timeout; got %d", timeout.Load().Milliseconds())
+	}
+	if !reflect.DeepEqual([]string(strs), []string{"a", "b,c"}) {
+		t.Fatalf("unexpected array.str; got %q", strs)
+	}
+	if !reflect.DeepEqual([]int(ints), []int{1, 2}) {
+		t.Fatalf("unexpected array.int; got %d", ints)
+	}
+}
+
+func TestFileSourceCheck(t *testing.T) {
+	f := func(name, data, errExpected string) {
+		t.Helper()
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.String("server.addr", "", "")
+		fs.Var(&ArrayString{}, "server.hosts", "")
+		path := writeTestFile(t, name, data)
+		src, err := NewFileSource(path)
+		if err == nil {
+			err = src.Check(fs)
+		}
+		if errExpected == "" {
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			return
+		}
+		if err == nil || !strings.Contains(err.Error(), errExpected) {
+			t.Fatalf("unexpected error; got %v; want %q", err, errExpected)
+		}
+	}
+
+	// YAML
+	f("config.yaml", "", "")
+	f("config.yaml", "server: {addr: ':80', hosts: [a, b]}", "")
+	f("config.yaml", "server.addr: ':80'", "")
+	f("config.yaml", "server:\n  # addr: ':80'\n", "")
+	f("config.yaml", "server.addr:\n", "")
+	f("config.yaml", "server:\n  addr: ':80'\n  port: 80\n", "config.yaml:3: unknown flag \"server.port\"")
+	f("config.yaml", "server:\n  addr: [a, b]\n", "config.yaml:2: flag \"server.addr\" doesn't accept multiple values")
+	f("config.yaml", "server:\n  addr: a\nserver.addr: b\n", "line 3: duplicate key \"server.addr\"")
+	f("config.yaml", "- a\n- b\n", "the top-level node must be a mapping")
+	f("config.yaml", "server: {hosts: [[a]]}", "unsupported nested value")
+	f("config.yaml", "server: [", "cannot parse config file")
+
+	// TOML
+	f("config.toml", "", "")
+	f("config.toml", "[server]\naddr = ':80'\nhosts = ['a', 'b']\n", "")
+	f("config.toml", "[server]\nport = 80\n", "config.toml: unknown flag \"server.port\"")
+	f("config.toml", "[server]\naddr = [1, 2]\n", "flag \"server.addr\" doesn't accept multiple values")
+	f("config.toml", "[server\n", "cannot parse config file")
+
+	// JSON
+	f("config.json", "", "")
+	f("config.json", `{"server": {"addr": ":80", "hosts": ["a", "b"]}}`, "")
+	f("config.json", "{\n  \"server\": {\n    \"port\": 80\n  }\n}", "config.json:3: unknown flag \"server.port\"")
+	f("config.json", `{"server": {"addr": ":80"}, "server.addr": ":81"}`, "duplicate key \"server.addr\"")
+	f("config.json", `{"server": {"hosts": [{"a": 1}]}}`, "unsupported nested value")
+	f("config.json", `[1, 2]`, "the top-level value must be an object")
+}
+
+func TestFileSourceLookup(t *testing.T) {
+	f := func(name, data string) {
+		t.Helper()
+		path := writeTestFile(t, name, data)
+		src, err := NewFileSource(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		expected := map[string]string{
+			"server.addr":    ":80",
+			"server.port":    "8080",
+			"server.tls":     "true",
+			"server.hosts":   `a,"b,c"`,
+			"limits.ratio":   "0.5",
+			"limits.timeout": "1d",
+		}
+		for name, valueExpected := range expected {
+			v, ok := src.Lookup(name)
+			if !ok {
+				t.Fatalf("cannot find value for %q", name)
+			}
+			if v != valueExpected {
+				t.Fatalf("unexpected value for %q; got %q; want %q", name, v, valueExpected)
+			}
+		}
+		if _, ok := src.Lookup("missing"); ok {
+			t.Fatalf("unexpected value found for missing flag")
+		}
+	}
+	f("config.yaml", `
+server:
+  addr: ":80"
+  port: 8080
+  tls: true
+  hosts: [a, "b,c"]
+limits:
+  ratio: 0.5
+  timeout: 1d
+`)
+	f("config.toml", `
+[server]
+addr = ":80"
+port = 8080
+tls = true
+hosts = ["a", "b,c"]
+
+[limits]
+ratio = 0.5
+timeout = "1d"
+`)
+	f("config.json", `{
+  "server": {"addr": ":80", "port": 8080, "tls": true, "hosts": ["a", "b,c"]},
+  "limits": {"ratio": 0.5, "timeout": "1d"}
+}`)
+}
+
+func TestParseFlagSetWithSources(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	a := fs.String("a", "", "")
+	b := fs.String("b", "", "")
+	c := fs.String("c", "default", "")
+	first := writeTestFile(t, "first.json", `{"a": "first"}`)
+	second := writeTestFile(t, "second.yaml", "a: second\nb: second\n")
+	src1, err := NewFileSource(first)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	src2, err := NewFileSource(second)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ParseFlagSetWithSources(fs, nil, src1, src2)
+	if *a != "first" || *b != "second" || *c != "default" {
+		t.Fatalf("unexpected values; got %q, %q, %q", *a, *b, *c)
+	}
+}
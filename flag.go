@@ -2,92 +2,134 @@ package flagx
 
 import (
 	"flag"
-	"fmt"
 	"io"
 	"log"
 	"os"
-	"strings"
 )
 
-var (
-	envPrefix = flag.String("env.prefix", "", "Prefix for environment variables.")
-)
+// NewBool returns new bool flag with the given name, defaultValue and description.
+func NewBool(name string, defaultValue bool, description string) *bool {
+	return CommandLine.NewBool(name, defaultValue, description)
+}
+
+// NewString returns new string flag with the given name, defaultValue and description.
+func NewString(name string, defaultValue string, description string) *string {
+	return CommandLine.NewString(name, defaultValue, description)
+}
+
+// NewInt returns new int flag with the given name, defaultValue, description and optional opts.
+func NewInt(name string, defaultValue int, description string, opts ...Option) *int {
+	return CommandLine.NewInt(name, defaultValue, description, opts...)
+}
 
-var NewBool = flag.Bool
-var NewString = flag.String
-var NewInt = flag.Int
-var NewInt64 = flag.Int64
-var NewFloat = flag.Float64
+// NewInt64 returns new int64 flag with the given name, defaultValue, description and optional opts.
+func NewInt64(name string, defaultValue int64, description string, opts ...Option) *int64 {
+	return CommandLine.NewInt64(name, defaultValue, description, opts...)
+}
 
-// WriteFlags writes all the explicitly set flags to w.
+// NewFloat returns new float64 flag with the given name, defaultValue and description.
+func NewFloat(name string, defaultValue float64, description string) *float64 {
+	return CommandLine.NewFloat(name, defaultValue, description)
+}
+
+// WriteFlags writes all the explicitly set flags to w together with their origin.
 func WriteFlags(w io.Writer) {
-	flag.Visit(func(f *flag.Flag) {
-		lname := strings.ToLower(f.Name)
-		value := f.Value.String()
-		if IsSecretFlag(lname) {
-			value = "secret"
-		}
-		fmt.Fprintf(w, "-%s=%q\n", f.Name, value)
-	})
+	CommandLine.WriteFlags(w)
 }
 
 // Visit all the flag name and values
 func Visit(fn func(string, string)) {
-	flag.Visit(func(f *flag.Flag) {
-		lname := strings.ToLower(f.Name)
-		value := f.Value.String()
-		if IsSecretFlag(lname) {
-			value = "secret"
-		}
-		fn(lname, value)
-	})
+	CommandLine.Visit(fn)
 }
 
-// Parse parses environment vars and command-line flags.
+// VisitWithOrigin is like Visit, but passes the origin of every flag value to fn.
 //
-// Flags set via command-line override flags set via environment vars.
+// See Origin for details.
+func VisitWithOrigin(fn func(name, value, origin string)) {
+	CommandLine.VisitWithOrigin(fn)
+}
+
+// Parse parses environment vars, config file and command-line flags.
+//
+// Flags set via command-line override flags set via environment vars,
+// which override flags set via config file passed to -config.file.
 //
 // This function must be called instead of flag.Parse() before using any flags in the program.
 func Parse() {
-	ParseFlagSet(flag.CommandLine, os.Args[1:])
+	exitOnError(CommandLine.Parse(os.Args[1:]))
+}
+
+// ParseWithFile is like Parse, but reads flag values from the config file at path
+// if -config.file isn't set.
+func ParseWithFile(path string) {
+	exitOnError(CommandLine.ParseWithFile(os.Args[1:], path))
 }
 
 // ParseFlagSet parses the given args into the given fs.
+//
+// The process exits on parse errors. Use ParseFlagSetE for returning errors instead.
 func ParseFlagSet(fs *flag.FlagSet, args []string) {
-	if err := fs.Parse(args); err != nil {
+	exitOnError(ParseFlagSetE(fs, args))
+}
+
+// ParseFlagSetE is like ParseFlagSet, but returns an error instead of exiting.
+//
+// The returned error is *ParseError holding all the invalid values,
+// unless it is flag.ErrHelp returned when -h or -help is passed in args.
+func ParseFlagSetE(fs *flag.FlagSet, args []string) error {
+	return setFor(fs).Parse(args)
+}
+
+// ParseFlagSetWithFile parses the given args into the given fs.
+//
+// Flag values missing in args and environment vars are read from the config file at path.
+// The path is overridden by -config.file flag if it is registered in fs and set.
+func ParseFlagSetWithFile(fs *flag.FlagSet, args []string, path string) {
+	exitOnError(ParseFlagSetWithFileE(fs, args, path))
+}
+
+// ParseFlagSetWithFileE is like ParseFlagSetWithFile, but returns an error instead of exiting.
+//
+// See ParseFlagSetE for details on the returned error.
+func ParseFlagSetWithFileE(fs *flag.FlagSet, args []string, path string) error {
+	return setFor(fs).ParseWithFile(args, path)
+}
+
+// ParseFlagSetWithSources parses the given args into the given fs.
+//
+// Flag values missing in args are read from the given sources.
+// Sources are consulted in the given order, so the first source has the highest priority.
+func ParseFlagSetWithSources(fs *flag.FlagSet, args []string, sources ...Source) {
+	exitOnError(ParseFlagSetWithSourcesE(fs, args, sources...))
+}
+
+// ParseFlagSetWithSourcesE is like ParseFlagSetWithSources, but returns an error instead of exiting.
+//
+// See ParseFlagSetE for details on the returned error.
+func ParseFlagSetWithSourcesE(fs *flag.FlagSet, args []string, sources ...Source) error {
+	return setFor(fs).ParseWithSources(args, sources...)
+}
+
+func exitOnError(err error) {
+	if err == flag.ErrHelp {
+		// The usage has been already printed by fs.Parse.
+		os.Exit(0)
+	}
+	if err != nil {
 		// Do not use lib/logger here, since it is uninitialized yet.
-		log.Fatalf("cannot parse flags %q: %s", args, err)
+		log.Fatalf("%s", err)
 	}
-
-	// Remember explicitly set command-line flags.
-	flagsSet := make(map[string]bool)
-	fs.Visit(func(f *flag.Flag) {
-		flagsSet[f.Name] = true
-	})
-
-	// Obtain the remaining flag values from environment vars.
-	fs.VisitAll(func(f *flag.Flag) {
-		if flagsSet[f.Name] {
-			// The flag is explicitly set via command-line.
-			return
-		}
-		// Get flag value from environment var.
-		fname := getEnvFlagName(f.Name)
-		if v := os.Getenv(fname); v != "" {
-			if err := fs.Set(f.Name, v); err != nil {
-				// Do not use lib/logger here, since it is uninitialized yet.
-				log.Fatalf("cannot set flag %s to %q, which is read from env var %q: %s", f.Name, v, fname, err)
-			}
-		}
-	})
 }
 
-func getEnvFlagName(s string) string {
-	// Substitute dots with underscores, since env var names cannot contain dots.
-	// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/311#issuecomment-586354129 for details.
-	return strings.ToUpper(*envPrefix + strings.ReplaceAll(s, ".", "_"))
+func newParseError(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ParseError{
+		Errors: errs,
+	}
 }
 
-func envHelp(s string) string {
-	return fmt.Sprintf("(env: %s)", getEnvFlagName(s))
+func getEnvFlagName(s string) string {
+	return CommandLine.getEnvFlagName(s)
 }
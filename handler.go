@@ -0,0 +1,148 @@
+package flagx
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// FlagInfo describes a single flag.
+type FlagInfo struct {
+	// Name is the flag name.
+	Name string `json:"name"`
+
+	// Usage is the flag description.
+	Usage string `json:"usage"`
+
+	// Default is the default flag value.
+	Default string `json:"default"`
+
+	// Value is the current flag value.
+	Value string `json:"value"`
+
+	// Origin describes where the value came from. See Origin for details.
+	Origin string `json:"origin"`
+
+	// IsSet is set to true if the flag value isn't the default one.
+	IsSet bool `json:"is_set"`
+
+	// Redacted is set to true if Value and Default are replaced with "secret", since the flag is secret.
+	Redacted bool `json:"redacted"`
+}
+
+// Flags returns info about all the flags registered at CommandLine.
+//
+// See Set.Flags for details.
+func Flags() []FlagInfo {
+	return CommandLine.Flags()
+}
+
+// Flags returns info about all the registered flags sorted by name.
+//
+// Values of secret flags are redacted the same way as in WriteFlags and Visit.
+func (s *Set) Flags() []FlagInfo {
+	var fis []FlagInfo
+	s.fs.VisitAll(func(f *flag.Flag) {
+		fi := FlagInfo{
+			Name:    f.Name,
+			Usage:   f.Usage,
+			Default: redactValue(f.Value, f.DefValue),
+			Value:   redactValue(f.Value, f.Value.String()),
+			Origin:  s.Origin(f.Name),
+		}
+		fi.IsSet = fi.Origin != OriginDefault
+		if s.IsSecretFlag(f.Name) {
+			fi.Value = secretMarker
+			fi.Default = secretMarker
+			fi.Redacted = true
+		}
+		fis = append(fis, fi)
+	})
+	return fis
+}
+
+// Handler returns http.Handler serving all the flags registered at CommandLine.
+//
+// See Set.Handler for details.
+func Handler() http.Handler {
+	return CommandLine.Handler()
+}
+
+// Handler returns http.Handler serving all the registered flags with their defaults, current values and origins.
+//
+// The response format is selected by `format` query arg (`html`, `text` or `json`).
+// If the query arg is missing, then the format is selected by Accept request header,
+// falling back to `text`.
+//
+// Values of secret flags are redacted.
+func (s *Set) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fis := s.Flags()
+		switch getResponseFormat(r) {
+		case "json":
+			w.Header().Set("Content-Type", "application/json")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			_ = enc.Encode(fis)
+		case "html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = flagsHTMLTemplate.Execute(w, fis)
+		default:
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			writeFlagInfos(w, fis)
+		}
+	})
+}
+
+func getResponseFormat(r *http.Request) string {
+	switch format := r.URL.Query().Get("format"); format {
+	case "json", "html", "text":
+		return format
+	}
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "application/json"):
+		return "json"
+	case strings.Contains(accept, "text/html"):
+		return "html"
+	default:
+		return "text"
+	}
+}
+
+func writeFlagInfos(w io.Writer, fis []FlagInfo) {
+	for _, fi := range fis {
+		fmt.Fprintf(w, "-%s=%q (default %q, from %s)\n", fi.Name, fi.Value, fi.Default, fi.Origin)
+	}
+}
+
+var flagsHTMLTemplate = template.Must(template.New("flags").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Flags</title>
+<style>
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+tr.set td.value { font-weight: bold; }
+td.usage { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<table>
+<tr><th>Name</th><th>Value</th><th>Default</th><th>Origin</th><th>Description</th></tr>
+{{range .}}<tr{{if .IsSet}} class="set"{{end}}>
+<td>-{{.Name}}</td>
+<td class="value">{{if .Redacted}}<i>{{.Value}}</i>{{else}}{{.Value}}{{end}}</td>
+<td>{{if .Redacted}}<i>{{.Default}}</i>{{else}}{{.Default}}{{end}}</td>
+<td>{{.Origin}}</td>
+<td class="usage">{{.Usage}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,67 @@
+package flagx
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetHandler(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	s.NewString("server.addr", ":80", "the address")
+	s.NewString("db.password", "default-pass", "the password")
+	s.NewInt("workers", 4, "the number of workers")
+	if err := s.Parse([]string{"-server.addr=:8080", "-db.password=hunter2"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	f := func(target, accept, contentTypeExpected string) string {
+		t.Helper()
+		r := httptest.NewRequest("GET", target, nil)
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, r)
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, contentTypeExpected) {
+			t.Fatalf("unexpected Content-Type; got %q; want %q", ct, contentTypeExpected)
+		}
+		body := w.Body.String()
+		if strings.Contains(body, "hunter2") || strings.Contains(body, "default-pass") {
+			t.Fatalf("secret value must be redacted:\n%s", body)
+		}
+		return body
+	}
+
+	body := f("/flags", "", "text/plain")
+	if !strings.Contains(body, `-server.addr=":8080" (default ":80", from command line)`) {
+		t.Fatalf("unexpected text response:\n%s", body)
+	}
+	if !strings.Contains(body, `-workers="4" (default "4", from default)`) {
+		t.Fatalf("flags with default values must be served:\n%s", body)
+	}
+
+	body = f("/flags", "text/html,application/xhtml+xml", "text/html")
+	if !strings.Contains(body, "<td>-server.addr</td>") {
+		t.Fatalf("unexpected html response:\n%s", body)
+	}
+
+	for _, body := range []string{f("/flags?format=json", "", "application/json"), f("/flags", "application/json", "application/json")} {
+		var fis []FlagInfo
+		if err := json.Unmarshal([]byte(body), &fis); err != nil {
+			t.Fatalf("cannot parse json response: %s", err)
+		}
+		m := make(map[string]FlagInfo)
+		for _, fi := range fis {
+			m[fi.Name] = fi
+		}
+		if fi := m["db.password"]; !fi.Redacted || !fi.IsSet || fi.Value != "secret" {
+			t.Fatalf("unexpected info for secret flag: %+v", fi)
+		}
+		if fi := m["workers"]; fi.IsSet || fi.Value != "4" || fi.Origin != OriginDefault || fi.Usage != "the number of workers" {
+			t.Fatalf("unexpected info for default flag: %+v", fi)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package flagx
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WriteHelp writes help for the flags registered at CommandLine to w.
+//
+// See Set.WriteHelp for details.
+func WriteHelp(w io.Writer, filter string) {
+	CommandLine.WriteHelp(w, filter)
+}
+
+// WriteHelp writes help for the registered flags to w.
+//
+// Flags are grouped by the first segment of their dotted names, i.e. `log.*` and `server.*`.
+// Every flag is printed together with its type and environment var name,
+// while the description is wrapped to the terminal width.
+//
+// If filter isn't empty, then only flags containing filter in their names are printed.
+// The filter may be passed via `-help=filter` command-line flag, i.e. `-help=server`.
+func (s *Set) WriteHelp(w io.Writer, filter string) {
+	width := terminalWidth(w)
+	filter = strings.ToLower(filter)
+	found := false
+	for _, g := range s.flagDocGroups() {
+		var fds []flagDoc
+		for _, fd := range g.flags {
+			if strings.Contains(strings.ToLower(fd.name), filter) {
+				fds = append(fds, fd)
+			}
+		}
+		if len(fds) == 0 {
+			continue
+		}
+		found = true
+
+		if g.prefix == "" {
+			fmt.Fprintf(w, "\nGeneral flags:\n")
+		} else {
+			fmt.Fprintf(w, "\n%s flags:\n", g.prefix)
+		}
+		heads := make([]string, len(fds))
+		headWidth := 0
+		for i, fd := range fds {
+			heads[i] = "-" + fd.name
+			if fd.typ != "bool" {
+				heads[i] += " " + fd.typ
+			}
+			headWidth = max(headWidth, len(heads[i]))
+		}
+		for i, fd := range fds {
+			fmt.Fprintf(w, "  %-*s  %s\n", headWidth, heads[i], fd.env)
+			usage := fd.usage
+			switch {
+			case fd.secret:
+				usage += " (secret)"
+			case fd.defaultValue != "" && !(fd.typ == "bool" && fd.defaultValue == "false"):
+				usage += fmt.Sprintf(" (default %q)", fd.defaultValue)
+			}
+			for _, line := range wrapText(usage, width-len(helpIndent)) {
+				fmt.Fprintf(w, "%s%s\n", helpIndent, line)
+			}
+		}
+	}
+	if !found {
+		fmt.Fprintf(w, "\nNo flags matching %q found\n", filter)
+	}
+}
+
+const helpIndent = "      "
+
+// wrapText splits s into lines not exceeding width, breaking lines at spaces.
+//
+// Explicit line breaks in s are preserved. Words longer than width are put on their own lines.
+func wrapText(s string, width int) []string {
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			if line != "" && len(line)+1+len(word) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// terminalWidth returns the width of the terminal w writes to.
+//
+// The width may be overridden via COLUMNS environment var.
+// 80 is returned if the width cannot be determined.
+func terminalWidth(w io.Writer) int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return max(n, minHelpWidth)
+	}
+	if f, ok := w.(*os.File); ok {
+		if n := getTerminalWidth(f.Fd()); n > 0 {
+			return max(n, minHelpWidth)
+		}
+	}
+	return 80
+}
+
+const minHelpWidth = 40
+
+// getHelpFilter returns the filter passed via `-help=filter` in args.
+//
+// An empty string is returned if args contain no help flag or it has no filter.
+func getHelpFilter(args []string) string {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, ok := strings.Cut(arg, "=")
+		if !isHelpArg(name) || !ok {
+			continue
+		}
+		if _, err := strconv.ParseBool(value); err == nil {
+			return ""
+		}
+		return value
+	}
+	return ""
+}
+
+// printUsage prints the default usage for s with help filtered by the filter passed via `-help=filter`.
+func (s *Set) printUsage() {
+	w := s.fs.Output()
+	if name := s.fs.Name(); name != "" {
+		fmt.Fprintf(w, "Usage of %s:\n", name)
+	} else {
+		fmt.Fprintf(w, "Usage:\n")
+	}
+	s.WriteHelp(w, s.helpFilter)
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd
+
+package flagx
+
+// getTerminalWidth returns 0, since terminal width detection isn't supported on this platform.
+func getTerminalWidth(fd uintptr) int {
+	return 0
+}
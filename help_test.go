@@ -0,0 +1,110 @@
+package flagx
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	f := func(s string, width int, expected []string) {
+		t.Helper()
+		result := wrapText(s, width)
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("unexpected result for wrapText(%q, %d); got %q; want %q", s, width, result, expected)
+		}
+	}
+	f("", 10, []string{""})
+	f("foo bar", 10, []string{"foo bar"})
+	f("foo bar baz", 10, []string{"foo bar", "baz"})
+	f("foo  bar\nbaz", 10, []string{"foo bar", "baz"})
+	f("verylongword foo", 5, []string{"verylongword", "foo"})
+}
+
+func TestGetHelpFilter(t *testing.T) {
+	f := func(args []string, expected string) {
+		t.Helper()
+		result := getHelpFilter(args)
+		if result != expected {
+			t.Fatalf("unexpected filter for %q; got %q; want %q", args, result, expected)
+		}
+	}
+	f(nil, "")
+	f([]string{"-help"}, "")
+	f([]string{"-help=true"}, "")
+	f([]string{"-foo=bar", "-help=server"}, "server")
+	f([]string{"--help=log"}, "log")
+	f([]string{"-h=server.addr"}, "server.addr")
+	f([]string{"-helper=server"}, "")
+	f([]string{"--", "-help=server"}, "")
+}
+
+func TestSetWriteHelp(t *testing.T) {
+	t.Setenv("COLUMNS", "50")
+
+	s := NewSet("app", flag.ContinueOnError)
+	s.NewString("server.addr", ":80", "the `address` to listen on, which is described by a long description")
+	s.NewArrayString("server.tags", "the tags")
+	s.NewString("auth.password", "qwerty", "the password")
+	s.NewBool("debug", false, "whether to enable debug mode")
+	s.NewInt("observer.workers", 4, "the number of workers")
+	f := func(filter, expected string) {
+		t.Helper()
+		var bb bytes.Buffer
+		s.WriteHelp(&bb, filter)
+		if result := bb.String(); result != expected {
+			t.Fatalf("unexpected help for filter %q;\ngot\n%s\nwant\n%s", filter, result, expected)
+		}
+	}
+	f("server", `
+observer flags:
+  -observer.workers int  OBSERVER_WORKERS
+      the number of workers (default "4")
+
+server flags:
+  -server.addr string            SERVER_ADDR
+      the address to listen on, which is described
+      by a long description (default ":80")
+  -server.tags array of strings  SERVER_TAGS
+      the tags
+      Supports an array of values separated by
+      comma or specified via multiple flags.
+`)
+	f("AUTH", `
+auth flags:
+  -auth.password string  AUTH_PASSWORD
+      the password (secret)
+`)
+	f("debug", `
+General flags:
+  -debug  DEBUG
+      whether to enable debug mode
+`)
+	f("missing", `
+No flags matching "missing" found
+`)
+}
+
+func TestSetParseHelpFilter(t *testing.T) {
+	t.Setenv("COLUMNS", "80")
+
+	s := NewSet("app", flag.ContinueOnError)
+	s.NewString("server.addr", ":80", "the address to listen on")
+	s.NewString("auth.password", "qwerty", "the password")
+	var bb bytes.Buffer
+	s.FlagSet().SetOutput(&bb)
+	if err := s.Parse([]string{"-help=auth"}); !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("unexpected error; got %v; want %v", err, flag.ErrHelp)
+	}
+	expected := `Usage of app:
+
+auth flags:
+  -auth.password string  AUTH_PASSWORD
+      the password (secret)
+`
+	if result := bb.String(); result != expected {
+		t.Fatalf("unexpected usage;\ngot\n%s\nwant\n%s", result, expected)
+	}
+}
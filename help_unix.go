@@ -0,0 +1,20 @@
+//go:build linux || darwin || freebsd
+
+package flagx
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// getTerminalWidth returns the width of the terminal at fd or 0 if fd isn't a terminal.
+func getTerminalWidth(fd uintptr) int {
+	var ws struct {
+		row, col, xpixel, ypixel uint16
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0
+	}
+	return int(ws.col)
+}
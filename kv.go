@@ -0,0 +1,407 @@
+package flagx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DuplicateKeyPolicy defines how Map flags handle duplicate keys.
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicateKeyLast keeps the last value for duplicate keys. This is the default policy.
+	DuplicateKeyLast DuplicateKeyPolicy = iota
+
+	// DuplicateKeyFirst keeps the first value for duplicate keys.
+	DuplicateKeyFirst
+
+	// DuplicateKeyError rejects values with duplicate keys.
+	DuplicateKeyError
+)
+
+// KeyValueSeparator returns an option, which sets the separator between keys and values
+// for Map and ArrayKV flags. The default separator is `=`.
+//
+// Spaces around keys and values are trimmed, so `-header='X-Scope: 1'` may be parsed with `:` separator.
+func KeyValueSeparator(sep string) Option {
+	return func(o *flagOptions) {
+		o.kvSeparator = sep
+	}
+}
+
+// PairSeparator returns an option, which sets the separator between key=value pairs
+// for Map and ArrayKV flags. The default separator is comma.
+func PairSeparator(sep byte) Option {
+	return func(o *flagOptions) {
+		o.pairSeparator = sep
+	}
+}
+
+// OnDuplicateKey returns an option, which sets the policy for duplicate keys in Map flags.
+func OnDuplicateKey(policy DuplicateKeyPolicy) Option {
+	return func(o *flagOptions) {
+		o.duplicateKeys = policy
+	}
+}
+
+// kvFormat contains separators for Map and ArrayKV flags.
+type kvFormat struct {
+	kvSeparator   string
+	pairSeparator byte
+}
+
+func (o *flagOptions) kvFormat() kvFormat {
+	kf := kvFormat{
+		kvSeparator:   o.kvSeparator,
+		pairSeparator: o.pairSeparator,
+	}
+	if kf.kvSeparator == "" {
+		kf.kvSeparator = "="
+	}
+	if kf.pairSeparator == 0 {
+		kf.pairSeparator = ','
+	}
+	return kf
+}
+
+func (kf kvFormat) help() string {
+	return fmt.Sprintf("\nSupports `map` of key%svalue pairs separated by %q or specified via multiple flags.", kf.kvSeparator, kf.pairSeparator)
+}
+
+// parse parses key-value pairs from value.
+//
+// Pairs are split according to parseArrayValues rules, so keys and values may be quoted.
+func (kf kvFormat) parse(value string) ([]KV, error) {
+	var kvs []KV
+	for _, pair := range parseArrayValuesWithSeparator(value, kf.pairSeparator) {
+		k, v, ok := kf.cut(pair)
+		if !ok {
+			return nil, fmt.Errorf("missing %q separator between key and value in %q", kf.kvSeparator, pair)
+		}
+		k = unquoteArrayValue(strings.TrimSpace(k))
+		if k == "" {
+			return nil, fmt.Errorf("missing key in %q", pair)
+		}
+		kvs = append(kvs, KV{
+			Key:   k,
+			Value: unquoteArrayValue(strings.TrimSpace(v)),
+		})
+	}
+	return kvs, nil
+}
+
+// cut splits pair around the first key-value separator outside quotes and braces.
+func (kf kvFormat) cut(pair string) (string, string, bool) {
+	idx := 0
+	for idx < len(pair) {
+		if strings.HasPrefix(pair[idx:], kf.kvSeparator) {
+			return pair[:idx], pair[idx+len(kf.kvSeparator):], true
+		}
+		ch := pair[idx]
+		idx++
+		if closeQuote, ok := closeQuotes[ch]; ok {
+			idx += indexCloseQuote(pair[idx:], closeQuote)
+		}
+	}
+	return pair, "", false
+}
+
+// format returns string representation for kvs, which may be parsed back by parse.
+func (kf kvFormat) format(kvs []KV) string {
+	a := make([]string, len(kvs))
+	for i, kv := range kvs {
+		k, v := kv.Key, kv.Value
+		if strings.ContainsAny(k, string(kf.pairSeparator)+`'"{[(`+"\n") || strings.Contains(k, kf.kvSeparator) || k != strings.TrimSpace(k) {
+			k = fmt.Sprintf("%q", k)
+		}
+		if strings.ContainsAny(v, string(kf.pairSeparator)+`'"{[(`+"\n") || v != strings.TrimSpace(v) {
+			v = fmt.Sprintf("%q", v)
+		}
+		a[i] = k + kf.kvSeparator + v
+	}
+	return strings.Join(a, string(kf.pairSeparator))
+}
+
+// KV is a key-value pair held by ArrayKV flag.
+type KV struct {
+	Key   string
+	Value string
+}
+
+// NewArrayKV returns new ArrayKV with the given name, description and optional opts.
+func NewArrayKV(name, description string, opts ...Option) *ArrayKV {
+	return CommandLine.NewArrayKV(name, description, opts...)
+}
+
+// NewArrayKV returns new ArrayKV with the given name, description and optional opts.
+//
+// KeyValueSeparator and PairSeparator options are supported.
+func (s *Set) NewArrayKV(name, description string, opts ...Option) *ArrayKV {
+	kf := applyOptions(opts).kvFormat()
+	description += kf.help()
+	a := &ArrayKV{
+		format: kf,
+	}
+	s.fs.Var(a, name, description)
+	return a
+}
+
+// ArrayKV is a flag that holds an ordered array of key-value pairs.
+//
+// It may be set either by specifying multiple flags with the given name or by joining pairs by comma:
+//
+//	-header='X-Scope: 1' -header='X-Tenant: 2'
+//	-label=env=prod,team=infra
+//
+// Pairs are split the same way as ArrayString values, so values may contain commas inside quotes.
+// Duplicate keys are preserved. The environment variable for the flag must contain pairs in the same format.
+//
+// Use Snapshot for reading the pairs from goroutines running concurrently with Reload.
+type ArrayKV struct {
+	format kvFormat
+
+	kvs Array[KV]
+}
+
+// Snapshot returns a copy of the stored pairs.
+//
+// It is safe calling Snapshot concurrently with Set and Reload.
+func (a *ArrayKV) Snapshot() []KV {
+	return a.kvs.Snapshot()
+}
+
+// String implements flag.Value interface
+func (a *ArrayKV) String() string {
+	return a.format.format(a.kvs.load())
+}
+
+// Set implements flag.Value interface
+func (a *ArrayKV) Set(value string) error {
+	kvs, err := a.format.parse(value)
+	if err != nil {
+		return err
+	}
+	a.kvs.add(kvs)
+	return nil
+}
+
+func (a *ArrayKV) replace(value string) error {
+	kvs, err := a.format.parse(value)
+	if err != nil {
+		return err
+	}
+	a.kvs.store(kvs)
+	return nil
+}
+
+// NewMap returns new Map with string values with the given name, description and optional opts.
+func NewMap(name, description string, opts ...Option) *Map[string] {
+	return CommandLine.NewMap(name, description, opts...)
+}
+
+// NewMap returns new Map with string values with the given name, description and optional opts.
+//
+// KeyValueSeparator, PairSeparator and OnDuplicateKey options are supported.
+func (s *Set) NewMap(name, description string, opts ...Option) *Map[string] {
+	o := applyOptions(opts)
+	return newMap(s, name, description, o, "", func(v string) (string, error) {
+		return v, nil
+	})
+}
+
+// NewMapDuration returns new Map with Duration values with the given name, description and optional opts.
+func NewMapDuration(name, description string, opts ...Option) *Map[time.Duration] {
+	return CommandLine.NewMapDuration(name, description, opts...)
+}
+
+// NewMapDuration returns new Map with Duration values with the given name, description and optional opts.
+//
+// Values are parsed the same way as Duration flag values.
+// KeyValueSeparator, PairSeparator, OnDuplicateKey, AllowNegative, MinDuration, MaxDuration
+// and Validate[time.Duration] options are supported. The validation options are applied to every value.
+func (s *Set) NewMapDuration(name, description string, opts ...Option) *Map[time.Duration] {
+	o := applyOptions(opts)
+	validators, help := o.durationChecks(name)
+	return newMap(s, name, description, o, help, func(v string) (time.Duration, error) {
+		d := Duration{
+			allowNegative: o.allowNegative,
+			validators:    validators,
+		}
+		if err := d.Set(v); err != nil {
+			return 0, err
+		}
+		return d.Load(), nil
+	})
+}
+
+// NewMapBytes returns new Map with Bytes values with the given name, description and optional opts.
+func NewMapBytes(name, description string, opts ...Option) *Map[int64] {
+	return CommandLine.NewMapBytes(name, description, opts...)
+}
+
+// NewMapBytes returns new Map with Bytes values with the given name, description and optional opts.
+//
+// Values are parsed the same way as Bytes flag values.
+// KeyValueSeparator, PairSeparator, OnDuplicateKey, Min, Max and Validate[int64] options are supported.
+// The validation options are applied to every value.
+func (s *Set) NewMapBytes(name, description string, opts ...Option) *Map[int64] {
+	o := applyOptions(opts)
+	validators, help := o.intChecks(name, formatBytes)
+	return newMap(s, name, description, o, arrayBytesHelp+help, func(v string) (int64, error) {
+		b := Bytes{
+			validators: validators,
+		}
+		if err := b.Set(v); err != nil {
+			return 0, err
+		}
+		return b.Load(), nil
+	})
+}
+
+func newMap[V any](s *Set, name, description string, o *flagOptions, help string, parse func(v string) (V, error)) *Map[V] {
+	kf := o.kvFormat()
+	description += kf.help() + help
+	m := &Map[V]{
+		format:        kf,
+		duplicateKeys: o.duplicateKeys,
+		parse:         parse,
+	}
+	s.fs.Var(m, name, description)
+	return m
+}
+
+// Map is a flag that holds a map of keys to values of type V.
+//
+// It may be set the same way as ArrayKV. Duplicate keys are handled according to OnDuplicateKey option.
+// The environment variable for the flag must contain pairs in the same format, i.e. `LABEL=env=prod,team=infra`.
+//
+// Use Get or Snapshot for reading the values from goroutines running concurrently with Reload.
+type Map[V any] struct {
+	format        kvFormat
+	duplicateKeys DuplicateKeyPolicy
+	parse         func(v string) (V, error)
+
+	// mu serializes updates of state.
+	mu sync.Mutex
+
+	// state holds the stored values. The stored state is never modified, so it may be read without locking.
+	state atomic.Pointer[mapState[V]]
+}
+
+// mapState contains values for Map.
+type mapState[V any] struct {
+	// kvs contains raw pairs in the order they were set, without duplicates.
+	kvs    []KV
+	values map[string]V
+}
+
+// load returns the stored state. The returned state mustn't be modified.
+func (m *Map[V]) load() *mapState[V] {
+	if st := m.state.Load(); st != nil {
+		return st
+	}
+	return &mapState[V]{}
+}
+
+// Get returns the value for the given key.
+//
+// It is safe calling Get concurrently with Set and Reload.
+func (m *Map[V]) Get(key string) (V, bool) {
+	v, ok := m.load().values[key]
+	return v, ok
+}
+
+// Keys returns sorted keys.
+//
+// It is safe calling Keys concurrently with Set and Reload.
+func (m *Map[V]) Keys() []string {
+	values := m.load().values
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Snapshot returns a copy of the stored values.
+//
+// It is safe calling Snapshot concurrently with Set and Reload.
+func (m *Map[V]) Snapshot() map[string]V {
+	st := m.load()
+	values := make(map[string]V, len(st.values))
+	for k, v := range st.values {
+		values[k] = v
+	}
+	return values
+}
+
+// String implements flag.Value interface
+//
+// Values are returned in the form they were set.
+func (m *Map[V]) String() string {
+	return m.format.format(m.load().kvs)
+}
+
+// Set implements flag.Value interface
+func (m *Map[V]) Set(value string) error {
+	return m.set(value, false)
+}
+
+func (m *Map[V]) replace(value string) error {
+	return m.set(value, true)
+}
+
+func (m *Map[V]) set(value string, reset bool) error {
+	kvs, err := m.format.parse(value)
+	if err != nil {
+		return err
+	}
+	parsed := make([]V, len(kvs))
+	for i, kv := range kvs {
+		v, err := m.parse(kv.Value)
+		if err != nil {
+			return fmt.Errorf("cannot parse value for key %q: %w", kv.Key, err)
+		}
+		parsed[i] = v
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var newKVs []KV
+	newValues := make(map[string]V)
+	if !reset {
+		st := m.load()
+		newKVs = append(newKVs, st.kvs...)
+		for k, v := range st.values {
+			newValues[k] = v
+		}
+	}
+	for i, kv := range kvs {
+		if _, ok := newValues[kv.Key]; ok {
+			switch m.duplicateKeys {
+			case DuplicateKeyFirst:
+				continue
+			case DuplicateKeyError:
+				return fmt.Errorf("duplicate key %q", kv.Key)
+			}
+			for j := range newKVs {
+				if newKVs[j].Key == kv.Key {
+					newKVs[j] = kv
+				}
+			}
+		} else {
+			newKVs = append(newKVs, kv)
+		}
+		newValues[kv.Key] = parsed[i]
+	}
+	m.state.Store(&mapState[V]{
+		kvs:    newKVs,
+		values: newValues,
+	})
+	return nil
+}
@@ -0,0 +1,143 @@
+package flagx
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestArrayKVSet(t *testing.T) {
+	f := func(opts []Option, values []string, expected []KV, expectedString string) {
+		t.Helper()
+		s := NewSet("test", flag.ContinueOnError)
+		a := s.NewArrayKV("kv", "the pairs", opts...)
+		for _, v := range values {
+			if err := a.Set(v); err != nil {
+				t.Fatalf("unexpected error in a.Set(%q): %s", v, err)
+			}
+		}
+		if result := a.Snapshot(); !reflect.DeepEqual(result, expected) {
+			t.Fatalf("unexpected pairs; got %q; want %q", result, expected)
+		}
+		if result := a.String(); result != expectedString {
+			t.Fatalf("unexpected String(); got %q; want %q", result, expectedString)
+		}
+		// Verify that String result may be parsed back.
+		b := s.NewArrayKV("kv2", "", opts...)
+		if err := b.Set(expectedString); err != nil {
+			t.Fatalf("cannot parse String() result: %s", err)
+		}
+		if result := b.Snapshot(); !reflect.DeepEqual(result, expected) {
+			t.Fatalf("unexpected pairs after parsing String() result; got %q; want %q", result, expected)
+		}
+	}
+	f(nil, []string{"env=prod,team=infra"}, []KV{{"env", "prod"}, {"team", "infra"}}, "env=prod,team=infra")
+	f(nil, []string{"a=1", "a=2"}, []KV{{"a", "1"}, {"a", "2"}}, "a=1,a=2")
+	f(nil, []string{`x="1,2",y='b',z=[c,d]`, "w=a=b"}, []KV{{"x", "1,2"}, {"y", "b"}, {"z", "[c,d]"}, {"w", "a=b"}}, `x="1,2",y=b,z="[c,d]",w=a=b`)
+	f(nil, []string{`"a=b"=c`, `'d=e'="f=g"`}, []KV{{"a=b", "c"}, {"d=e", "f=g"}}, `"a=b"=c,"d=e"=f=g`)
+	f([]Option{KeyValueSeparator(":")}, []string{"X-Scope: 1", "X-Tenant: 2, X-Foo: bar"}, []KV{{"X-Scope", "1"}, {"X-Tenant", "2"}, {"X-Foo", "bar"}}, "X-Scope:1,X-Tenant:2,X-Foo:bar")
+	f([]Option{KeyValueSeparator(":"), PairSeparator(';')}, []string{"X-A: 1,2; X-B: 3"}, []KV{{"X-A", "1,2"}, {"X-B", "3"}}, "X-A:1,2;X-B:3")
+}
+
+func TestArrayKVSetFailure(t *testing.T) {
+	f := func(value string) {
+		t.Helper()
+		var a ArrayKV
+		a.format = applyOptions(nil).kvFormat()
+		if err := a.Set(value); err == nil {
+			t.Fatalf("expecting non-nil error in a.Set(%q)", value)
+		}
+	}
+	f("foo")
+	f("a=1,foo")
+	f("=1")
+}
+
+func TestMapDuplicateKeys(t *testing.T) {
+	f := func(policy DuplicateKeyPolicy, values []string, expected map[string]string, expectedString string, expectError bool) {
+		t.Helper()
+		s := NewSet("test", flag.ContinueOnError)
+		m := s.NewMap("labels", "the labels", OnDuplicateKey(policy))
+		var err error
+		for _, v := range values {
+			if err = m.Set(v); err != nil {
+				break
+			}
+		}
+		if expectError != (err != nil) {
+			t.Fatalf("unexpected error: %v; expectError=%v", err, expectError)
+		}
+		if result := m.Snapshot(); !reflect.DeepEqual(result, expected) {
+			t.Fatalf("unexpected values; got %v; want %v", result, expected)
+		}
+		if result := m.String(); result != expectedString {
+			t.Fatalf("unexpected String(); got %q; want %q", result, expectedString)
+		}
+	}
+	f(DuplicateKeyLast, []string{"a=1,b=2", "a=3"}, map[string]string{"a": "3", "b": "2"}, "a=3,b=2", false)
+	f(DuplicateKeyFirst, []string{"a=1,b=2", "a=3"}, map[string]string{"a": "1", "b": "2"}, "a=1,b=2", false)
+	f(DuplicateKeyError, []string{"a=1,b=2", "b=4,a=3"}, map[string]string{"a": "1", "b": "2"}, "a=1,b=2", true)
+	f(DuplicateKeyError, []string{"a=1,a=2"}, map[string]string{}, "", true)
+}
+
+func TestMapTyped(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	timeouts := s.NewMapDuration("timeouts", "the timeouts", MaxDuration("1d"))
+	limits := s.NewMapBytes("limits", "the limits", Min(1*KiB))
+
+	if err := s.Parse([]string{"-timeouts=read=5s,write=1m", "-timeouts=idle=2h", "-limits=body=4MiB,header=1KiB"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expectedTimeouts := map[string]time.Duration{"read": 5 * time.Second, "write": time.Minute, "idle": 2 * time.Hour}
+	if result := timeouts.Snapshot(); !reflect.DeepEqual(result, expectedTimeouts) {
+		t.Fatalf("unexpected timeouts; got %v; want %v", result, expectedTimeouts)
+	}
+	if d, ok := timeouts.Get("idle"); !ok || d != 2*time.Hour {
+		t.Fatalf("unexpected Get result; got %v, %v; want %v, true", d, ok, 2*time.Hour)
+	}
+	if keys := timeouts.Keys(); !reflect.DeepEqual(keys, []string{"idle", "read", "write"}) {
+		t.Fatalf("unexpected keys: %q", keys)
+	}
+	if result := limits.String(); result != "body=4MiB,header=1KiB" {
+		t.Fatalf("unexpected String(); got %q; want %q", result, "body=4MiB,header=1KiB")
+	}
+	if n, _ := limits.Get("body"); n != 4*MiB {
+		t.Fatalf("unexpected limit; got %d; want %d", n, 4*MiB)
+	}
+
+	if err := timeouts.Set("x=2d"); err == nil {
+		t.Fatalf("expecting non-nil error for the value exceeding MaxDuration")
+	}
+	if err := limits.Set("x=1"); err == nil {
+		t.Fatalf("expecting non-nil error for the value smaller than Min")
+	}
+	if err := limits.Set("x=foo"); err == nil {
+		t.Fatalf("expecting non-nil error for invalid value")
+	}
+	if _, ok := limits.Get("x"); ok {
+		t.Fatalf("rejected value mustn't be stored")
+	}
+}
+
+func TestMapEnvAndFile(t *testing.T) {
+	path := writeTestFile(t, "config.yaml", `
+labels:
+  - env=prod
+  - team=infra
+`)
+	t.Setenv("HEADERS", "X-A: 1, X-B: 2")
+
+	s := NewSet("test", flag.ContinueOnError)
+	labels := s.NewMap("labels", "the labels")
+	headers := s.NewArrayKV("headers", "the headers", KeyValueSeparator(":"))
+	if err := s.ParseWithFile(nil, path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result := labels.Snapshot(); !reflect.DeepEqual(result, map[string]string{"env": "prod", "team": "infra"}) {
+		t.Fatalf("unexpected labels: %v", result)
+	}
+	if result := headers.Snapshot(); !reflect.DeepEqual(result, []KV{{"X-A", "1"}, {"X-B", "2"}}) {
+		t.Fatalf("unexpected headers: %v", result)
+	}
+}
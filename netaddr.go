@@ -0,0 +1,446 @@
+package flagx
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// NewHostPort returns new `host:port` flag with the given name, defaultValue and description.
+func NewHostPort(name, defaultValue, description string) *HostPort {
+	return CommandLine.NewHostPort(name, defaultValue, description)
+}
+
+// NewHostPort returns new `host:port` flag with the given name, defaultValue and description.
+//
+// The host may be empty, i.e. `:8080` for listening on all the interfaces.
+// An empty defaultValue leaves the flag unset.
+func (s *Set) NewHostPort(name, defaultValue, description string) *HostPort {
+	hp := &HostPort{}
+	if err := hp.Set(defaultValue); err != nil {
+		panic(fmt.Sprintf("BUG: invalid default value %q for flag %s: %s", defaultValue, name, err))
+	}
+	s.fs.Var(hp, name, description)
+	return hp
+}
+
+// HostPort is a flag for holding a network address in `host:port` form.
+//
+// Use Load, Host or Port for reading the value from goroutines running concurrently with Set, i.e. during Reload.
+type HostPort struct {
+	value atomic.Pointer[hostPort]
+}
+
+type hostPort struct {
+	host string
+	port int
+}
+
+func (hp *HostPort) load() hostPort {
+	if v := hp.value.Load(); v != nil {
+		return *v
+	}
+	return hostPort{}
+}
+
+// Load returns the stored address in normalized `host:port` form.
+//
+// An empty string is returned if the address isn't set.
+//
+// It is safe calling Load concurrently with Set.
+func (hp *HostPort) Load() string {
+	v := hp.value.Load()
+	if v == nil {
+		return ""
+	}
+	return net.JoinHostPort(v.host, strconv.Itoa(v.port))
+}
+
+// Host returns the stored host.
+//
+// It is safe calling Host concurrently with Set.
+func (hp *HostPort) Host() string {
+	return hp.load().host
+}
+
+// Port returns the stored port.
+//
+// It is safe calling Port concurrently with Set.
+func (hp *HostPort) Port() int {
+	return hp.load().port
+}
+
+// String implements flag.Value interface
+func (hp *HostPort) String() string {
+	return hp.Load()
+}
+
+// Set implements flag.Value interface
+//
+// Hostnames are lowercased, IP addresses are normalized, i.e. `[::0001]:08080` is stored as `[::1]:8080`.
+func (hp *HostPort) Set(value string) error {
+	if value == "" {
+		hp.value.Store(nil)
+		return nil
+	}
+	host, portStr, err := net.SplitHostPort(value)
+	if err != nil {
+		return fmt.Errorf("cannot parse address %q: %w", value, err)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid port %q in address %q; it must be a number in the range [0..65535]", portStr, value)
+	}
+	host, err = normalizeHost(host)
+	if err != nil {
+		return err
+	}
+	hp.value.Store(&hostPort{
+		host: host,
+		port: int(port),
+	})
+	return nil
+}
+
+// normalizeHost returns normalized IP address or lowercased hostname for host.
+func normalizeHost(host string) (string, error) {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return ip.String(), nil
+	}
+	for i := 0; i < len(host); i++ {
+		c := host[i]
+		if !isDecimalChar(c) && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && c != '.' && c != '-' && c != '_' {
+			return "", fmt.Errorf("invalid host %q; it must be an IP address or a hostname", host)
+		}
+	}
+	return strings.ToLower(host), nil
+}
+
+// NewIP returns new `ip` flag with the given name, defaultValue and description.
+func NewIP(name, defaultValue, description string) *IP {
+	return CommandLine.NewIP(name, defaultValue, description)
+}
+
+// NewIP returns new `ip` flag with the given name, defaultValue and description.
+//
+// Both IPv4 and IPv6 addresses are supported. An empty defaultValue leaves the flag unset.
+func (s *Set) NewIP(name, defaultValue, description string) *IP {
+	ip := &IP{}
+	if err := ip.Set(defaultValue); err != nil {
+		panic(fmt.Sprintf("BUG: invalid default value %q for flag %s: %s", defaultValue, name, err))
+	}
+	s.fs.Var(ip, name, description)
+	return ip
+}
+
+// IP is a flag for holding an IP address.
+//
+// Use Load for reading the value from goroutines running concurrently with Set, i.e. during Reload.
+type IP struct {
+	value atomic.Pointer[netip.Addr]
+}
+
+// Load returns the stored address.
+//
+// Invalid netip.Addr is returned if the address isn't set.
+//
+// It is safe calling Load concurrently with Set.
+func (ip *IP) Load() netip.Addr {
+	if v := ip.value.Load(); v != nil {
+		return *v
+	}
+	return netip.Addr{}
+}
+
+// String implements flag.Value interface
+func (ip *IP) String() string {
+	if v := ip.value.Load(); v != nil {
+		return v.String()
+	}
+	return ""
+}
+
+// Set implements flag.Value interface
+func (ip *IP) Set(value string) error {
+	if value == "" {
+		ip.value.Store(nil)
+		return nil
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return fmt.Errorf("cannot parse IP address: %w", err)
+	}
+	ip.value.Store(&addr)
+	return nil
+}
+
+// NewCIDR returns new `cidr` flag with the given name, defaultValue and description.
+func NewCIDR(name, defaultValue, description string) *CIDR {
+	return CommandLine.NewCIDR(name, defaultValue, description)
+}
+
+// NewCIDR returns new `cidr` flag with the given name, defaultValue and description.
+//
+// Values are normalized to network addresses, i.e. `10.1.2.3/8` is stored as `10.0.0.0/8`.
+// IP addresses without prefix length are stored as single-address networks, i.e. `10.1.2.3/32`.
+// An empty defaultValue leaves the flag unset.
+func (s *Set) NewCIDR(name, defaultValue, description string) *CIDR {
+	c := &CIDR{}
+	if err := c.Set(defaultValue); err != nil {
+		panic(fmt.Sprintf("BUG: invalid default value %q for flag %s: %s", defaultValue, name, err))
+	}
+	s.fs.Var(c, name, description)
+	return c
+}
+
+// CIDR is a flag for holding a network in CIDR notation.
+//
+// Use Load or Contains for reading the value from goroutines running concurrently with Set, i.e. during Reload.
+type CIDR struct {
+	value atomic.Pointer[netip.Prefix]
+}
+
+// Load returns the stored network.
+//
+// Invalid netip.Prefix is returned if the network isn't set.
+//
+// It is safe calling Load concurrently with Set.
+func (c *CIDR) Load() netip.Prefix {
+	if v := c.value.Load(); v != nil {
+		return *v
+	}
+	return netip.Prefix{}
+}
+
+// Contains returns true if the stored network contains addr.
+//
+// It is safe calling Contains concurrently with Set.
+func (c *CIDR) Contains(addr netip.Addr) bool {
+	return c.Load().Contains(addr)
+}
+
+// String implements flag.Value interface
+func (c *CIDR) String() string {
+	if v := c.value.Load(); v != nil {
+		return v.String()
+	}
+	return ""
+}
+
+// Set implements flag.Value interface
+func (c *CIDR) Set(value string) error {
+	if value == "" {
+		c.value.Store(nil)
+		return nil
+	}
+	p, err := parseCIDR(value)
+	if err != nil {
+		return err
+	}
+	c.value.Store(&p)
+	return nil
+}
+
+// parseCIDR parses s as a network in CIDR notation and returns it normalized to the network address.
+func parseCIDR(s string) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("cannot parse CIDR: %w", err)
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("cannot parse CIDR: %w", err)
+	}
+	return p.Masked(), nil
+}
+
+// NewArrayCIDR returns new ArrayCIDR with the given name and description.
+func NewArrayCIDR(name, description string) *ArrayCIDR {
+	return CommandLine.NewArrayCIDR(name, description)
+}
+
+// NewArrayCIDR returns new ArrayCIDR with the given name and description.
+//
+// See NewCIDR for details on values.
+func (s *Set) NewArrayCIDR(name, description string) *ArrayCIDR {
+	description += arrayHelp
+	var a ArrayCIDR
+	s.fs.Var(&a, name, description)
+	return &a
+}
+
+// ArrayCIDR is a flag that holds an array of networks in CIDR notation.
+//
+// Has the same api as Array.
+type ArrayCIDR Array[netip.Prefix]
+
+// Snapshot returns a copy of a.
+//
+// It is safe calling Snapshot concurrently with Set and Reload.
+func (a *ArrayCIDR) Snapshot() []netip.Prefix {
+	return (*Array[netip.Prefix])(a).Snapshot()
+}
+
+// Contains returns true if any of the stored networks contains addr.
+//
+// It is safe calling Contains concurrently with Set and Reload.
+func (a *ArrayCIDR) Contains(addr netip.Addr) bool {
+	for _, p := range (*Array[netip.Prefix])(a).load() {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// String implements flag.Value interface
+func (a *ArrayCIDR) String() string {
+	return (*Array[netip.Prefix])(a).String()
+}
+
+// Set implements flag.Value interface
+func (a *ArrayCIDR) Set(value string) error {
+	return (*Array[netip.Prefix])(a).Set(value)
+}
+
+func (a *ArrayCIDR) replace(value string) error {
+	return (*Array[netip.Prefix])(a).replace(value)
+}
+
+// NewURL returns new `url` flag with the given name, defaultValue, allowed schemes and description.
+func NewURL(name, defaultValue string, schemes []string, description string) *URL {
+	return CommandLine.NewURL(name, defaultValue, schemes, description)
+}
+
+// NewURL returns new `url` flag with the given name, defaultValue, allowed schemes and description.
+//
+// The flag accepts only absolute urls with the given schemes. Any scheme is allowed if schemes is empty.
+// An empty defaultValue leaves the flag unset.
+//
+// Passwords in url userinfo are redacted by WriteFlags, Visit, Handler and generated docs.
+// Register the flag via RegisterSecretFlag if the whole url must be hidden.
+func (s *Set) NewURL(name, defaultValue string, schemes []string, description string) *URL {
+	if len(schemes) > 0 {
+		description += fmt.Sprintf("\nSupported schemes: %s.", strings.Join(schemes, ", "))
+	}
+	u := &URL{
+		schemes: schemes,
+	}
+	if err := u.Set(defaultValue); err != nil {
+		panic(fmt.Sprintf("BUG: invalid default value for flag %s: %s", name, err))
+	}
+	s.fs.Var(u, name, description)
+	return u
+}
+
+// URL is a flag for holding an absolute url.
+//
+// Use Load for reading the value from goroutines running concurrently with Set, i.e. during Reload.
+type URL struct {
+	schemes []string
+
+	value atomic.Pointer[url.URL]
+}
+
+// Load returns a copy of the stored url.
+//
+// nil is returned if the url isn't set.
+//
+// It is safe calling Load concurrently with Set.
+func (u *URL) Load() *url.URL {
+	v := u.value.Load()
+	if v == nil {
+		return nil
+	}
+	vCopy := *v
+	return &vCopy
+}
+
+// String implements flag.Value interface
+func (u *URL) String() string {
+	if v := u.value.Load(); v != nil {
+		return v.String()
+	}
+	return ""
+}
+
+// Set implements flag.Value interface
+//
+// Scheme and host are lowercased.
+func (u *URL) Set(value string) error {
+	if value == "" {
+		u.value.Store(nil)
+		return nil
+	}
+	v, err := parseURL(value)
+	if err != nil {
+		return err
+	}
+	if len(u.schemes) > 0 {
+		ok := false
+		for _, scheme := range u.schemes {
+			if strings.EqualFold(scheme, v.Scheme) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("unsupported url scheme %q; supported schemes: %s", v.Scheme, strings.Join(u.schemes, ", "))
+		}
+	}
+	u.value.Store(v)
+	return nil
+}
+
+// parseURL parses s as an absolute url with lowercased scheme and host.
+//
+// The returned errors do not contain s, since it may contain a password.
+func parseURL(s string) (*url.URL, error) {
+	v, err := url.Parse(s)
+	if err != nil {
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			err = ue.Err
+		}
+		return nil, fmt.Errorf("cannot parse url: %w", err)
+	}
+	if v.Scheme == "" {
+		return nil, fmt.Errorf("missing scheme in url; it must be absolute, i.e. `https://example.com`")
+	}
+	v.Host = strings.ToLower(v.Host)
+	return v, nil
+}
+
+// redactValue returns value for the flag v with passwords in url userinfo replaced by `xxxxx`.
+//
+// value is returned as is for flags, which cannot hold urls, or if it cannot be parsed.
+func redactValue(v flag.Value, value string) string {
+	switch unwrapValue(v).(type) {
+	case *URL:
+		u, err := url.Parse(value)
+		if err != nil {
+			return value
+		}
+		return u.Redacted()
+	case *Array[*url.URL]:
+		items := parseArrayValues(value)
+		for i, item := range items {
+			u, err := url.Parse(item)
+			if err != nil {
+				return value
+			}
+			items[i] = u.Redacted()
+		}
+		return strings.Join(items, ",")
+	}
+	return value
+}
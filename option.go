@@ -0,0 +1,218 @@
+package flagx
+
+import (
+	"fmt"
+	"time"
+)
+
+// Size units for Bytes flags.
+const (
+	KB int64 = 1000
+	MB       = 1000 * KB
+	GB       = 1000 * MB
+	TB       = 1000 * GB
+
+	KiB int64 = 1024
+	MiB       = 1024 * KiB
+	GiB       = 1024 * MiB
+	TiB       = 1024 * GiB
+)
+
+// Option is an optional setting for flags created via NewBytes, NewDuration, NewInt, NewInt64,
+// NewArrayDuration, NewArrayKV and NewMap* functions.
+//
+// Options are applied to values from all the sources, including command line, env vars and config files.
+type Option func(o *flagOptions)
+
+type flagOptions struct {
+	min, max *int64
+
+	minDuration, maxDuration string
+	allowNegative            bool
+
+	intValidators      []func(n int64) error
+	durationValidators []func(d time.Duration) error
+
+	kvSeparator   string
+	pairSeparator byte
+	duplicateKeys DuplicateKeyPolicy
+}
+
+// Min returns an option, which rejects values smaller than n for Bytes, int and int64 flags.
+//
+// The allowed range is appended to the flag description.
+func Min(n int64) Option {
+	return func(o *flagOptions) {
+		o.min = &n
+	}
+}
+
+// Max returns an option, which rejects values bigger than n for Bytes, int and int64 flags.
+//
+// The allowed range is appended to the flag description.
+func Max(n int64) Option {
+	return func(o *flagOptions) {
+		o.max = &n
+	}
+}
+
+// MinDuration returns an option, which rejects values smaller than d for Duration flags.
+//
+// d is in the format supported by Duration, i.e. `1h` or `30d`.
+// The allowed range is appended to the flag description.
+func MinDuration(d string) Option {
+	return func(o *flagOptions) {
+		o.minDuration = d
+	}
+}
+
+// MaxDuration returns an option, which rejects values bigger than d for Duration flags.
+//
+// d is in the format supported by Duration, i.e. `1h` or `30d`.
+// The allowed range is appended to the flag description.
+func MaxDuration(d string) Option {
+	return func(o *flagOptions) {
+		o.maxDuration = d
+	}
+}
+
+// AllowNegative returns an option, which allows negative values for Duration, ArrayDuration and NewMapDuration flags,
+// i.e. `-1h` or `2h-5m` for time offsets.
+func AllowNegative() Option {
+	return func(o *flagOptions) {
+		o.allowNegative = true
+	}
+}
+
+// Validate returns an option, which checks parsed flag values with fn.
+//
+// T must be int64 for Bytes, int and int64 flags, and time.Duration for Duration flags.
+// The value is rejected if fn returns an error.
+func Validate[T int64 | time.Duration](fn func(v T) error) Option {
+	return func(o *flagOptions) {
+		switch fn := any(fn).(type) {
+		case func(int64) error:
+			o.intValidators = append(o.intValidators, fn)
+		case func(time.Duration) error:
+			o.durationValidators = append(o.durationValidators, fn)
+		}
+	}
+}
+
+func applyOptions(opts []Option) *flagOptions {
+	var o flagOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &o
+}
+
+// intChecks returns validators for Bytes, int or int64 flag with the given name.
+//
+// It also returns the allowed range description, which must be appended to the flag description.
+// format is used for formatting the range bounds.
+func (o *flagOptions) intChecks(name string, format func(n int64) string) ([]func(n int64) error, string) {
+	if o.minDuration != "" || o.maxDuration != "" || o.allowNegative || len(o.durationValidators) > 0 {
+		panic(fmt.Sprintf("BUG: duration options cannot be used for flag %s; use Min, Max or Validate[int64] instead", name))
+	}
+	validators := o.intValidators
+	var minHelp, maxHelp string
+	if o.min != nil {
+		minN := *o.min
+		minHelp = format(minN)
+		validators = append(validators, func(n int64) error {
+			if n < minN {
+				return fmt.Errorf("the value cannot be smaller than %s", minHelp)
+			}
+			return nil
+		})
+	}
+	if o.max != nil {
+		maxN := *o.max
+		maxHelp = format(maxN)
+		validators = append(validators, func(n int64) error {
+			if n > maxN {
+				return fmt.Errorf("the value cannot be bigger than %s", maxHelp)
+			}
+			return nil
+		})
+	}
+	return validators, rangeHelp(minHelp, maxHelp)
+}
+
+// durationChecks returns validators for Duration flag with the given name.
+//
+// It also returns the allowed range description, which must be appended to the flag description.
+func (o *flagOptions) durationChecks(name string) ([]func(d time.Duration) error, string) {
+	if o.min != nil || o.max != nil || len(o.intValidators) > 0 {
+		panic(fmt.Sprintf("BUG: int options cannot be used for duration flag %s; use MinDuration, MaxDuration or Validate[time.Duration] instead", name))
+	}
+	parse := func(s string) time.Duration {
+		d, err := parseDuration(s, o.allowNegative)
+		if err != nil {
+			panic(fmt.Sprintf("BUG: cannot parse duration bound %q for flag %s: %s", s, name, err))
+		}
+		return d
+	}
+	validators := o.durationValidators
+	if o.minDuration != "" {
+		minHelp := o.minDuration
+		minD := parse(minHelp)
+		validators = append(validators, func(d time.Duration) error {
+			if d < minD {
+				return fmt.Errorf("the duration cannot be smaller than %s", minHelp)
+			}
+			return nil
+		})
+	}
+	if o.maxDuration != "" {
+		maxHelp := o.maxDuration
+		maxD := parse(maxHelp)
+		validators = append(validators, func(d time.Duration) error {
+			if d > maxD {
+				return fmt.Errorf("the duration cannot be bigger than %s", maxHelp)
+			}
+			return nil
+		})
+	}
+	return validators, rangeHelp(o.minDuration, o.maxDuration)
+}
+
+// rangeHelp returns description for the range with the given bounds. Empty bounds are unlimited.
+func rangeHelp(minValue, maxValue string) string {
+	switch {
+	case minValue != "" && maxValue != "":
+		return fmt.Sprintf("\nThe allowed range is from %s to %s.", minValue, maxValue)
+	case minValue != "":
+		return fmt.Sprintf("\nThe minimum allowed value is %s.", minValue)
+	case maxValue != "":
+		return fmt.Sprintf("\nThe maximum allowed value is %s.", maxValue)
+	default:
+		return ""
+	}
+}
+
+func validateInt(validators []func(n int64) error, n int64) error {
+	for _, v := range validators {
+		if err := v(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatBytes returns human-readable representation for n bytes, which may be parsed by Bytes.
+func formatBytes(n int64) string {
+	units := []struct {
+		suffix string
+		size   int64
+	}{
+		{"TiB", TiB}, {"TB", TB}, {"GiB", GiB}, {"GB", GB}, {"MiB", MiB}, {"MB", MB}, {"KiB", KiB}, {"KB", KB},
+	}
+	for _, u := range units {
+		if n != 0 && n%u.size == 0 {
+			return fmt.Sprintf("%d%s", n/u.size, u.suffix)
+		}
+	}
+	return fmt.Sprintf("%d", n)
+}
@@ -0,0 +1,188 @@
+package flagx
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatBytes(t *testing.T) {
+	f := func(n int64, expected string) {
+		t.Helper()
+		if result := formatBytes(n); result != expected {
+			t.Fatalf("unexpected formatBytes(%d); got %q; want %q", n, result, expected)
+		}
+	}
+	f(0, "0")
+	f(123, "123")
+	f(2000, "2KB")
+	f(2048, "2KiB")
+	f(4*GiB, "4GiB")
+	f(3*TB, "3TB")
+	f(1500*KiB, "1500KiB")
+}
+
+func TestSetOptionsRange(t *testing.T) {
+	newSet := func() *Set {
+		s := NewSet("test", flag.ContinueOnError)
+		s.NewBytes("cache.size", 64*MiB, "the cache size", Min(1*MiB), Max(4*GiB))
+		s.NewDuration("retention", "1d", "the retention", MinDuration("1h"), MaxDuration("30d"))
+		s.NewInt("workers", 4, "the number of workers", Min(1))
+		s.NewInt64("offset", 0, "the offset", Max(100))
+		s.FlagSet().SetOutput(io.Discard)
+		return s
+	}
+
+	fOK := func(args []string) {
+		t.Helper()
+		if err := newSet().Parse(args); err != nil {
+			t.Fatalf("unexpected error for %q: %s", args, err)
+		}
+	}
+	fOK(nil)
+	fOK([]string{"-cache.size=1MiB", "-retention=30d", "-workers=1", "-offset=-5"})
+	fOK([]string{"-cache.size=4GiB", "-retention=1h", "-workers=100", "-offset=100"})
+
+	fError := func(args []string, expected string) {
+		t.Helper()
+		err := newSet().Parse(args)
+		if err == nil {
+			t.Fatalf("expecting non-nil error for %q", args)
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("unexpected error for %q; got %q; want it to contain %q", args, err, expected)
+		}
+	}
+	fError([]string{"-cache.size=1KiB"}, "the value cannot be smaller than 1MiB")
+	fError([]string{"-cache.size=5GiB"}, "the value cannot be bigger than 4GiB")
+	fError([]string{"-retention=31d"}, "the duration cannot be bigger than 30d")
+	fError([]string{"-retention=59m"}, "the duration cannot be smaller than 1h")
+	fError([]string{"-workers=0"}, "the value cannot be smaller than 1")
+	fError([]string{"-offset=101"}, "the value cannot be bigger than 100")
+
+	// Values from env vars are validated too.
+	t.Setenv("CACHE_SIZE", "8GiB")
+	err := newSet().Parse(nil)
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expecting *ParseError; got %v", err)
+	}
+	fes := pe.FlagErrors()
+	if len(fes) != 1 || fes[0].Name != "cache.size" || fes[0].Source != "env CACHE_SIZE" {
+		t.Fatalf("unexpected flag errors: %v", fes)
+	}
+}
+
+func TestSetOptionsValidate(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	b := s.NewBytes("size", 1024, "the size", Validate(func(n int64) error {
+		if n%1024 != 0 {
+			return errors.New("the value must be a multiple of 1KiB")
+		}
+		return nil
+	}))
+	d := s.NewDuration("interval", "1m", "the interval", Validate(func(d time.Duration) error {
+		if d%time.Minute != 0 {
+			return errors.New("the value must be a multiple of a minute")
+		}
+		return nil
+	}))
+	if err := b.Set("2KiB"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := b.Set("1000"); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if b.Load() != 2048 {
+		t.Fatalf("unexpected value after the rejected Set; got %d; want %d", b.Load(), 2048)
+	}
+	if err := d.Set("2m"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := d.Set("90"); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestSetOptionsHelp(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	s.NewBytes("cache.size", 64*MiB, "the cache size", Min(1*MiB), Max(4*GiB))
+	s.NewDuration("retention", "1d", "the retention", MaxDuration("30d"))
+	s.NewInt("workers", 4, "the number of workers", Min(1))
+
+	f := func(name, expected string) {
+		t.Helper()
+		usage := s.FlagSet().Lookup(name).Usage
+		if !strings.HasSuffix(usage, expected) {
+			t.Fatalf("unexpected usage for -%s; got %q; want suffix %q", name, usage, expected)
+		}
+	}
+	f("cache.size", "\nThe allowed range is from 1MiB to 4GiB.")
+	f("retention", "\nThe maximum allowed value is 30d.")
+	f("workers", "\nThe minimum allowed value is 1.")
+}
+
+func TestSetOptionsMismatch(t *testing.T) {
+	f := func(register func(s *Set)) {
+		t.Helper()
+		defer func() {
+			t.Helper()
+			if r := recover(); r == nil {
+				t.Fatalf("expecting panic")
+			}
+		}()
+		register(NewSet("test", flag.ContinueOnError))
+	}
+	f(func(s *Set) { s.NewDuration("d", "1h", "", Min(1)) })
+	f(func(s *Set) { s.NewBytes("b", 1, "", MaxDuration("1h")) })
+	f(func(s *Set) { s.NewDuration("d", "1h", "", MaxDuration("foo")) })
+	f(func(s *Set) { s.NewInt("n", 1, "", AllowNegative()) })
+}
+
+func TestSetOptionsInvalidDefault(t *testing.T) {
+	f := func(register func(s *Set)) {
+		t.Helper()
+		defer func() {
+			t.Helper()
+			if r := recover(); r == nil {
+				t.Fatalf("expecting panic")
+			}
+		}()
+		register(NewSet("test", flag.ContinueOnError))
+	}
+	f(func(s *Set) { s.NewBytes("b", 1, "", Min(1024)) })
+	f(func(s *Set) { s.NewBytes("b", 1<<30, "", Max(1<<20)) })
+	f(func(s *Set) { s.NewDuration("d", "1h", "", MinDuration("1d")) })
+	f(func(s *Set) { s.NewDuration("d", "1w", "", MaxDuration("1d")) })
+	f(func(s *Set) { s.NewInt("workers", 0, "", Min(1)) })
+	f(func(s *Set) { s.NewInt64("n", 1<<40, "", Max(1<<20)) })
+	f(func(s *Set) {
+		s.NewDuration("d", "1h", "", Validate(func(d time.Duration) error { return errors.New("rejected") }))
+	})
+}
+
+func TestSetOptionsAllowNegative(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	offset := s.NewDuration("offset", "-1h", "the offset", AllowNegative(), MinDuration("-1d"))
+	retention := s.NewDuration("retention", "1d", "the retention")
+	s.FlagSet().SetOutput(io.Discard)
+
+	if got, want := offset.Load(), -time.Hour; got != want {
+		t.Fatalf("unexpected default value; got %s; want %s", got, want)
+	}
+	if err := s.Parse([]string{"-offset=-90"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := offset.Load(), -90*time.Second; got != want {
+		t.Fatalf("unexpected value; got %s; want %s", got, want)
+	}
+	if err := offset.Set("-2d"); err == nil {
+		t.Fatalf("expecting non-nil error for the value exceeding MinDuration")
+	}
+	if err := retention.Set("-1h"); err == nil {
+		t.Fatalf("expecting non-nil error for negative value without AllowNegative")
+	}
+}
@@ -0,0 +1,70 @@
+package flagx
+
+import (
+	"flag"
+)
+
+const (
+	// OriginDefault is the origin of flags, which weren't set, so they hold the default value.
+	OriginDefault = "default"
+
+	// OriginCommandLine is the origin of flags set via command-line.
+	OriginCommandLine = "command line"
+)
+
+// Origin returns where the value of the flag with the given name at CommandLine came from.
+//
+// Use ForFlagSet(fs).Origin for flags parsed via ParseFlagSet*. See Set.Origin for details.
+func Origin(name string) string {
+	return CommandLine.Origin(name)
+}
+
+// Origin returns where the value of the flag with the given name came from.
+//
+// It returns OriginCommandLine for flags set via command-line, OriginDefault for flags,
+// which weren't set, and Source.Provenance for flags read from sources,
+// i.e. `env SERVER_ADDR` or `file config.yaml:12`.
+//
+// The origin is recorded during Parse* calls.
+func (s *Set) Origin(name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if origin, ok := s.origins[name]; ok {
+		return origin
+	}
+	return OriginDefault
+}
+
+func (s *Set) setOrigin(name, origin string) {
+	s.mu.Lock()
+	s.origins[name] = origin
+	s.mu.Unlock()
+}
+
+// visitSet calls fn for flags, which are explicitly set via command-line or sources.
+//
+// Unlike flag.FlagSet.Visit, it relies on the recorded origins, since Reload doesn't mark flags as set.
+func (s *Set) visitSet(fn func(f *flag.Flag)) {
+	actual := make(map[string]bool)
+	s.fs.Visit(func(f *flag.Flag) {
+		actual[f.Name] = true
+	})
+	s.fs.VisitAll(func(f *flag.Flag) {
+		s.mu.Lock()
+		origin, ok := s.origins[f.Name]
+		s.mu.Unlock()
+		if ok && origin != OriginDefault || !ok && actual[f.Name] {
+			fn(f)
+		}
+	})
+}
+
+// resetOrigins marks flags as set via command-line if they are explicitly set, and as default otherwise.
+func (s *Set) resetOrigins() {
+	s.fs.VisitAll(func(f *flag.Flag) {
+		s.setOrigin(f.Name, OriginDefault)
+	})
+	s.fs.Visit(func(f *flag.Flag) {
+		s.setOrigin(f.Name, OriginCommandLine)
+	})
+}
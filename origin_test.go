@@ -0,0 +1,36 @@
+package flagx
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestOrigin(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("origin.cmd", "", "")
+	fs.String("origin.env", "", "")
+	fs.String("origin.file", "", "")
+	fs.String("origin.default", "", "")
+	path := writeTestFile(t, "config.yaml", "origin:\n  cmd: a\n  env: b\n  file: c\n")
+
+	t.Setenv("ORIGIN_ENV", "foo")
+	if err := ParseFlagSetWithFileE(fs, []string{"-origin.cmd=bar"}, path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f := func(name, originExpected string) {
+		t.Helper()
+		if origin := ForFlagSet(fs).Origin(name); origin != originExpected {
+			t.Fatalf("unexpected origin for %q; got %q; want %q", name, origin, originExpected)
+		}
+	}
+	f("origin.cmd", OriginCommandLine)
+	f("origin.env", "env ORIGIN_ENV")
+	f("origin.file", "file "+path+":4")
+	f("origin.default", OriginDefault)
+	f("origin.missing", OriginDefault)
+
+	// Origins of flags parsed via other flag sets mustn't leak into CommandLine.
+	if origin := Origin("origin.cmd"); origin != OriginDefault {
+		t.Fatalf("unexpected origin at CommandLine; got %q; want %q", origin, OriginDefault)
+	}
+}
@@ -0,0 +1,80 @@
+package flagx
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WritePrometheus writes flags registered at CommandLine to w in Prometheus text exposition format.
+//
+// See Set.WritePrometheus for details.
+func WritePrometheus(w io.Writer) {
+	CommandLine.WritePrometheus(w)
+}
+
+// WritePrometheus writes all the flags to w in Prometheus text exposition format.
+//
+// Every flag is exported as `flag{name="...",value="...",is_set="true"} 1` gauge.
+// Values of secret flags are redacted the same way as in Visit.
+//
+// Additionally, Bytes flags are exported as `flag_bytes{name="..."}` gauges
+// and Duration flags and time.Duration fields bound via Bind are exported as `flag_duration_seconds{name="..."}` gauges,
+// so their values may be compared to runtime metrics in alerts. Secret flags aren't exported as numeric gauges.
+func (s *Set) WritePrometheus(w io.Writer) {
+	fis := s.Flags()
+	fmt.Fprintf(w, "# HELP flag Command-line flag value.\n")
+	fmt.Fprintf(w, "# TYPE flag gauge\n")
+	for _, fi := range fis {
+		fmt.Fprintf(w, "flag{name=\"%s\",value=\"%s\",is_set=\"%t\"} 1\n", escapeLabelValue(fi.Name), escapeLabelValue(fi.Value), fi.IsSet)
+	}
+
+	var bytesFlags, durationFlags []*flag.Flag
+	s.fs.VisitAll(func(f *flag.Flag) {
+		if s.IsSecretFlag(f.Name) {
+			return
+		}
+		if _, ok := unwrapValue(f.Value).(*Bytes); ok {
+			bytesFlags = append(bytesFlags, f)
+		}
+		if _, ok := durationValue(f.Value); ok {
+			durationFlags = append(durationFlags, f)
+		}
+	})
+	if len(bytesFlags) > 0 {
+		fmt.Fprintf(w, "# HELP flag_bytes Size flag value in bytes.\n")
+		fmt.Fprintf(w, "# TYPE flag_bytes gauge\n")
+		for _, f := range bytesFlags {
+			fmt.Fprintf(w, "flag_bytes{name=\"%s\"} %d\n", escapeLabelValue(f.Name), unwrapValue(f.Value).(*Bytes).Load())
+		}
+	}
+	if len(durationFlags) > 0 {
+		fmt.Fprintf(w, "# HELP flag_duration_seconds Duration flag value in seconds.\n")
+		fmt.Fprintf(w, "# TYPE flag_duration_seconds gauge\n")
+		for _, f := range durationFlags {
+			d, _ := durationValue(f.Value)
+			fmt.Fprintf(w, "flag_duration_seconds{name=\"%s\"} %g\n", escapeLabelValue(f.Name), d.Seconds())
+		}
+	}
+}
+
+// durationValue returns the value of v if it is Duration flag or time.Duration struct field bound via Bind.
+func durationValue(v flag.Value) (time.Duration, bool) {
+	switch v := unwrapValue(v).(type) {
+	case *Duration:
+		return v.Load(), true
+	case *reflectValue:
+		if v.v.Type() == durationType {
+			return time.Duration(v.v.Int()), true
+		}
+	}
+	return 0, false
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
@@ -0,0 +1,47 @@
+package flagx
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+	"time"
+)
+
+func TestSetWritePrometheus(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	s.NewBytes("limit", 1024, "")
+	s.NewDuration("retention", "1d", "")
+	s.NewString("label", "", "")
+	s.NewBytes("secret.size", 1, "")
+	var cfg struct {
+		Timeout time.Duration `default:"5s"`
+	}
+	if err := s.Bind(&cfg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := s.Parse([]string{"-limit=1MiB", "-label=a\"b\\c\nd", "-env.prefix=app_"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var bb bytes.Buffer
+	s.WritePrometheus(&bb)
+	expected := `# HELP flag Command-line flag value.
+# TYPE flag gauge
+flag{name="config.file",value="",is_set="false"} 1
+flag{name="env.prefix",value="app_",is_set="true"} 1
+flag{name="label",value="a\"b\\c\nd",is_set="true"} 1
+flag{name="limit",value="1MiB",is_set="true"} 1
+flag{name="retention",value="1d",is_set="false"} 1
+flag{name="secret.size",value="secret",is_set="false"} 1
+flag{name="timeout",value="5s",is_set="false"} 1
+# HELP flag_bytes Size flag value in bytes.
+# TYPE flag_bytes gauge
+flag_bytes{name="limit"} 1048576
+# HELP flag_duration_seconds Duration flag value in seconds.
+# TYPE flag_duration_seconds gauge
+flag_duration_seconds{name="retention"} 86400
+flag_duration_seconds{name="timeout"} 5
+`
+	if s := bb.String(); s != expected {
+		t.Fatalf("unexpected output;\ngot\n%s\nwant\n%s", s, expected)
+	}
+}
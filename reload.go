@@ -0,0 +1,336 @@
+package flagx
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Reloader is implemented by sources, which must be re-read on Set.Reload.
+//
+// Sources not implementing Reloader are reused as is, so they must return fresh values on every Lookup call.
+type Reloader interface {
+	// Reload returns a fresh copy of the source with re-read contents.
+	Reload() (Source, error)
+}
+
+// Reload returns a copy of s with the config file contents re-read.
+func (s *FileSource) Reload() (Source, error) {
+	return NewFileSource(s.path)
+}
+
+// replacer is implemented by flag values, which accumulate values on Set calls, such as Array* flags.
+type replacer interface {
+	// replace atomically replaces the accumulated values with the value.
+	replace(value string) error
+}
+
+// MarkReloadable marks flags with the given names as reloadable at CommandLine.
+//
+// See Set.MarkReloadable for details.
+func MarkReloadable(names ...string) {
+	CommandLine.MarkReloadable(names...)
+}
+
+// OnChange registers fn to be called when a flag value at CommandLine is changed by Reload.
+func OnChange(fn func(name, oldValue, newValue string)) {
+	CommandLine.OnChange(fn)
+}
+
+// Reload re-reads sources for CommandLine.
+//
+// See Set.Reload for details.
+func Reload() error {
+	return CommandLine.Reload()
+}
+
+// WatchReload reloads CommandLine flags on SIGHUP until ctx is done.
+//
+// See Set.WatchReload for details.
+func WatchReload(ctx context.Context, pollInterval time.Duration) {
+	CommandLine.WatchReload(ctx, pollInterval)
+}
+
+// MarkReloadable marks flags with the given names as reloadable.
+//
+// Only reloadable flags are updated by Reload.
+func (s *Set) MarkReloadable(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reloadable == nil {
+		s.reloadable = make(map[string]bool)
+	}
+	for _, name := range names {
+		s.reloadable[name] = true
+	}
+}
+
+// OnChange registers fn to be called when a flag value is changed by Reload.
+//
+// fn is called with the flag name and the old and new values as returned by flag.Value.String.
+// Values of secret flags are passed as is, so fn must take care of not exposing them.
+// Values of Secret flags are always passed as "secret".
+func (s *Set) OnChange(fn func(name, oldValue, newValue string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = append(s.onChange, fn)
+}
+
+// Reload re-reads the sources used by the last Parse* call and applies the new values to reloadable flags.
+//
+// Flags set via command-line aren't changed, since command-line has the highest priority.
+// The exception is `secretref://` values and `@/path/to/file` values for flags registered via EnableSecretFiles,
+// which are resolved again. See RegisterResolver.
+// Reloadable flags missing in all the sources are reset to their default values.
+//
+// The reload is atomic: if any value is rejected or the reloaded flags violate the registered constraints,
+// then all the already applied values are rolled back and *ParseError is returned.
+//
+// Callbacks registered via OnChange are called for the changed flags after the successful reload.
+func (s *Set) Reload() error {
+	// Reload calls are serialized, since the new values are resolved without holding s.mu.
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	s.mu.Lock()
+	parsedSources := s.parsedSources
+	s.mu.Unlock()
+	sources := make([]Source, 0, len(parsedSources))
+	var errs []error
+	for _, src := range parsedSources {
+		if r, ok := src.(Reloader); ok {
+			newSrc, err := r.Reload()
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			src = newSrc
+		}
+		if c, ok := src.(flagSetChecker); ok {
+			if err := c.Check(s.fs); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		sources = append(sources, src)
+	}
+	if len(errs) > 0 {
+		return newParseError(errs)
+	}
+
+	type update struct {
+		f *flag.Flag
+		// sourceValue is the value from the source. It is used in errors, so resolved secrets aren't exposed.
+		sourceValue string
+		value       string
+		origin      string
+	}
+	var updates []update
+	s.mu.Lock()
+	s.fs.VisitAll(func(f *flag.Flag) {
+		if !s.reloadable[f.Name] {
+			return
+		}
+		v, origin := f.DefValue, OriginDefault
+		if s.origins[f.Name] == OriginCommandLine {
+			// Flags set via command-line aren't changed, except of secret references and secret files, which are resolved again.
+			ref, ok := s.getCommandLineSecretRef(f.Name)
+			if !ok {
+				return
+			}
+			v, origin = ref, OriginCommandLine
+		} else {
+			for _, src := range sources {
+				if sv, ok := src.Lookup(f.Name); ok {
+					v, origin = sv, src.Provenance(f.Name)
+					break
+				}
+			}
+		}
+		updates = append(updates, update{
+			f:           f,
+			sourceValue: v,
+			value:       v,
+			origin:      origin,
+		})
+	})
+	s.mu.Unlock()
+
+	// Resolve the new values before taking s.mu, so slow resolvers don't block Origin, WriteFlags and other readers.
+	s.resetSecretRefsCache()
+	s.resetEncryptionKey()
+	for i := range updates {
+		u := &updates[i]
+		if u.origin == OriginDefault {
+			continue
+		}
+		v, err := s.resolveValue(u.f.Name, u.value)
+		if err != nil {
+			errs = append(errs, &FlagError{
+				Name:   u.f.Name,
+				Source: u.origin,
+				Value:  u.sourceValue,
+				Err:    err,
+				secret: s.IsSecretFlag(u.f.Name),
+				value:  u.f.Value,
+			})
+			continue
+		}
+		u.value = v
+	}
+	if len(errs) > 0 {
+		return newParseError(errs)
+	}
+
+	type change struct {
+		f        *flag.Flag
+		oldValue string
+		newValue string
+		origin   string
+	}
+	var changes []change
+	rollback := func() {
+		for i := len(changes) - 1; i >= 0; i-- {
+			c := changes[i]
+			_ = s.reloadFlagValue(c.f, c.oldValue)
+		}
+	}
+	s.mu.Lock()
+	for _, u := range updates {
+		f := u.f
+		oldValue := rawValue(f.Value)
+		if err := s.reloadFlagValue(f, u.value); err != nil {
+			errs = append(errs, &FlagError{
+				Name:   f.Name,
+				Source: u.origin,
+				Value:  u.sourceValue,
+				Err:    err,
+				secret: s.IsSecretFlag(f.Name),
+				value:  f.Value,
+			})
+			// Restore the value of the failed flag, since it may be partially updated.
+			_ = s.reloadFlagValue(f, oldValue)
+			break
+		}
+		changes = append(changes, change{
+			f:        f,
+			oldValue: oldValue,
+			newValue: rawValue(f.Value),
+			origin:   u.origin,
+		})
+	}
+	if len(errs) == 0 {
+		// Check the constraints against the reloaded flags, since reloadable flags may disappear from the sources.
+		newOrigins := make(map[string]string, len(changes))
+		for _, c := range changes {
+			newOrigins[c.f.Name] = c.origin
+		}
+		errs = s.checkConstraints(errs, func(name string) string {
+			if origin, ok := newOrigins[name]; ok {
+				return origin
+			}
+			if origin, ok := s.origins[name]; ok {
+				return origin
+			}
+			return OriginDefault
+		})
+	}
+	if len(errs) > 0 {
+		rollback()
+		s.mu.Unlock()
+		return newParseError(errs)
+	}
+	s.parsedSources = sources
+	for _, c := range changes {
+		s.origins[c.f.Name] = c.origin
+	}
+	callbacks := s.onChange
+	s.mu.Unlock()
+
+	for _, c := range changes {
+		if c.oldValue == c.newValue {
+			continue
+		}
+		oldValue, newValue := c.oldValue, c.newValue
+		if _, ok := c.f.Value.(*Secret); ok {
+			oldValue, newValue = secretMarker, secretMarker
+		}
+		for _, fn := range callbacks {
+			fn(c.f.Name, oldValue, newValue)
+		}
+	}
+	return nil
+}
+
+// reloadFlagValue sets f to v, dropping the previously accumulated values for Array* flags.
+//
+// The value is set directly instead of fs.Set, so flags reset to default or rolled back aren't marked as set.
+// Flags changed by Reload are made visible to Visit via their origins. See visitSet.
+func (s *Set) reloadFlagValue(f *flag.Flag, v string) error {
+	if r, ok := f.Value.(replacer); ok {
+		return r.replace(v)
+	}
+	return f.Value.Set(v)
+}
+
+// WatchReload calls Reload on every SIGHUP signal until ctx is done.
+//
+// If pollInterval is positive, then config files are checked for modifications
+// with the given interval, and Reload is called when they change.
+//
+// Reload errors are logged, and the previous flag values remain active.
+// The function blocks until ctx is done, so it is usually run in a separate goroutine.
+func (s *Set) WatchReload(ctx context.Context, pollInterval time.Duration) {
+	sighupCh := make(chan os.Signal, 1)
+	signal.Notify(sighupCh, syscall.SIGHUP)
+	defer signal.Stop(sighupCh)
+
+	var tickerCh <-chan time.Time
+	if pollInterval > 0 {
+		t := time.NewTicker(pollInterval)
+		defer t.Stop()
+		tickerCh = t.C
+	}
+	states := s.configFileStates()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighupCh:
+		case <-tickerCh:
+			newStates := s.configFileStates()
+			if newStates == states {
+				continue
+			}
+			states = newStates
+		}
+		if err := s.Reload(); err != nil {
+			log.Printf("cannot reload flags: %s", err)
+		}
+	}
+}
+
+// configFileStates returns a string describing modification times and sizes for all the config files.
+func (s *Set) configFileStates() string {
+	s.mu.Lock()
+	sources := s.parsedSources
+	s.mu.Unlock()
+	states := ""
+	for _, src := range sources {
+		p, ok := src.(interface{ Path() string })
+		if !ok {
+			continue
+		}
+		fi, err := os.Stat(p.Path())
+		if err != nil {
+			states += fmt.Sprintf("%s:missing;", p.Path())
+			continue
+		}
+		states += fmt.Sprintf("%s:%d:%d;", p.Path(), fi.ModTime().UnixNano(), fi.Size())
+	}
+	return states
+}
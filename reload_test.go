@@ -0,0 +1,231 @@
+package flagx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSetReload(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	limit := s.NewBytes("limit", 1024, "")
+	hosts := s.NewArrayString("hosts", "")
+	name := s.NewString("name", "default", "")
+	static := s.NewString("static", "", "")
+	cmd := s.NewString("cmd", "", "")
+	s.MarkReloadable("limit", "hosts", "name", "cmd")
+
+	type change struct {
+		name, oldValue, newValue string
+	}
+	var changes []change
+	s.OnChange(func(name, oldValue, newValue string) {
+		changes = append(changes, change{name, oldValue, newValue})
+	})
+
+	path := writeTestFile(t, "config.yaml", "limit: 1KiB\nhosts: [a, b]\nname: foo\nstatic: x\ncmd: y\n")
+	if err := s.ParseWithFile([]string{"-cmd=z"}, path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Successful reload
+	if err := os.WriteFile(path, []byte("limit: 2KiB\nhosts: [c]\nstatic: y\ncmd: w\n"), 0o600); err != nil {
+		t.Fatalf("cannot update config file: %s", err)
+	}
+	if err := s.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if limit.Load() != 2048 || !reflect.DeepEqual([]string(*hosts), []string{"c"}) || *name != "default" {
+		t.Fatalf("unexpected values after reload: %d, %q, %q", limit.Load(), *hosts, *name)
+	}
+	if *static != "x" || *cmd != "z" {
+		t.Fatalf("non-reloadable and command-line flags mustn't be changed; got %q, %q", *static, *cmd)
+	}
+	if origin := s.Origin("name"); origin != OriginDefault {
+		t.Fatalf("unexpected origin for name; got %q", origin)
+	}
+	expectedChanges := []change{
+		{"hosts", "a,b", "c"},
+		{"limit", "1KiB", "2KiB"},
+		{"name", "foo", "default"},
+	}
+	if !reflect.DeepEqual(changes, expectedChanges) {
+		t.Fatalf("unexpected changes;\ngot\n%q\nwant\n%q", changes, expectedChanges)
+	}
+
+	// Failed reload must leave all the values untouched
+	changes = nil
+	if err := os.WriteFile(path, []byte("hosts: [d, e]\nlimit: foo\nname: bar\n"), 0o600); err != nil {
+		t.Fatalf("cannot update config file: %s", err)
+	}
+	if err := s.Reload(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if limit.Load() != 2048 || !reflect.DeepEqual([]string(*hosts), []string{"c"}) || *name != "default" {
+		t.Fatalf("unexpected values after failed reload: %d, %q, %q", limit.Load(), *hosts, *name)
+	}
+	if len(changes) > 0 {
+		t.Fatalf("unexpected changes after failed reload: %q", changes)
+	}
+
+	// Broken config file
+	if err := os.WriteFile(path, []byte("hosts: ["), 0o600); err != nil {
+		t.Fatalf("cannot update config file: %s", err)
+	}
+	if err := s.Reload(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestSetReloadActualFlags(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	s.NewString("app.name", "default", "")
+	s.NewBytes("limit", 1024, "")
+	s.MarkReloadable("app.name", "limit")
+	path := writeTestFile(t, "config.yaml", "limit: 2KiB\n")
+	if err := s.ParseWithFile(nil, path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f := func(expected string) {
+		t.Helper()
+		var bb bytes.Buffer
+		s.WriteFlags(&bb)
+		if result := bb.String(); result != expected {
+			t.Fatalf("unexpected flags written;\ngot\n%s\nwant\n%s", result, expected)
+		}
+	}
+
+	// The rolled back flag mustn't be marked as set.
+	if err := os.WriteFile(path, []byte("app:\n  name: foo\nlimit: bar\n"), 0o600); err != nil {
+		t.Fatalf("cannot update config file: %s", err)
+	}
+	if err := s.Reload(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	s.FlagSet().Visit(func(f *flag.Flag) {
+		if f.Name == "app.name" {
+			t.Fatalf("the rolled back flag mustn't be marked as set")
+		}
+	})
+	f("-limit=\"2KiB\" (from file " + path + ":1)\n")
+
+	// The flag changed from the default value must be visible, while the flag reset to the default value mustn't.
+	if err := os.WriteFile(path, []byte("app:\n  name: foo\n"), 0o600); err != nil {
+		t.Fatalf("cannot update config file: %s", err)
+	}
+	if err := s.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f("-app.name=\"foo\" (from file " + path + ":2)\n")
+}
+
+func TestSetReloadConstraints(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	addr := s.NewString("db.addr", "", "")
+	s.MarkReloadable("db.addr")
+	s.Required("db.addr")
+	path := writeTestFile(t, "config.yaml", "db:\n  addr: localhost:5432\n")
+	if err := s.ParseWithFile(nil, path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The required flag disappeared from the config file
+	if err := os.WriteFile(path, []byte("db: {}\n"), 0o600); err != nil {
+		t.Fatalf("cannot update config file: %s", err)
+	}
+	err := s.Reload()
+	var pe *ParseError
+	if !errors.As(err, &pe) || len(pe.ConstraintErrors()) != 1 {
+		t.Fatalf("expecting *ParseError with a single constraint error; got %v", err)
+	}
+	if *addr != "localhost:5432" {
+		t.Fatalf("the value must be rolled back; got %q", *addr)
+	}
+	if origin := s.Origin("db.addr"); origin == OriginDefault {
+		t.Fatalf("the origin must be rolled back; got %q", origin)
+	}
+}
+
+func TestSetWatchReload(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	name := s.NewString("name", "", "")
+	s.MarkReloadable("name")
+	changed := make(chan string, 1)
+	s.OnChange(func(_, _, newValue string) {
+		changed <- newValue
+	})
+	path := writeTestFile(t, "config.yaml", "name: foo\n")
+	if err := s.ParseWithFile(nil, path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if *name != "foo" {
+		t.Fatalf("unexpected value; got %q", *name)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.WatchReload(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Give the watcher a chance to take the initial config file state.
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("name: foobar\n"), 0o600); err != nil {
+		t.Fatalf("cannot update config file: %s", err)
+	}
+	select {
+	case v := <-changed:
+		if v != "foobar" {
+			t.Fatalf("unexpected new value; got %q", v)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for the reload")
+	}
+}
+
+func TestSetReloadConcurrentReads(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	limit := s.NewBytes("limit", 0, "")
+	timeout := s.NewDuration("timeout", "1s", "")
+	hosts := s.NewArrayString("hosts", "")
+	s.MarkReloadable("limit", "timeout", "hosts")
+	path := writeTestFile(t, "config.yaml", "limit: 1\ntimeout: 1s\nhosts: [a]\n")
+	if err := s.ParseWithFile(nil, path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = limit.Load()
+			_ = timeout.Load()
+			_ = hosts.Snapshot()
+			_ = s.Origin("limit")
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		data := fmt.Sprintf("limit: %d\ntimeout: %ds\nhosts: [a, b%d]\n", i, i, i)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatalf("cannot update config file: %s", err)
+		}
+		if err := s.Reload(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	<-done
+	if limit.Load() != 9 || timeout.Load() != 9*time.Second || !reflect.DeepEqual(hosts.Snapshot(), []string{"a", "b9"}) {
+		t.Fatalf("unexpected values after reload: %d, %s, %q", limit.Load(), timeout.Load(), hosts.Snapshot())
+	}
+}
@@ -0,0 +1,172 @@
+package flagx
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnableSecretFiles allows reading values for flags with the given names at CommandLine from files.
+//
+// See Set.EnableSecretFiles for details.
+func EnableSecretFiles(names ...string) {
+	CommandLine.EnableSecretFiles(names...)
+}
+
+// EnableSecretFilesForSecretFlags allows reading values for all the secret flags at CommandLine from files.
+//
+// See Set.EnableSecretFilesForSecretFlags for details.
+func EnableSecretFilesForSecretFlags() {
+	CommandLine.EnableSecretFilesForSecretFlags()
+}
+
+// EnableSecretFiles allows reading values for flags with the given names from files.
+//
+// The value is read from the file if it is passed in `@/path/to/file` form via any source,
+// i.e. `-db.password=@/run/secrets/db`. Additionally, the value is read from the file
+// at the path in the env var with `_FILE` suffix, i.e. `DB_PASSWORD_FILE=/run/secrets/db`,
+// if the env var without the suffix is empty.
+//
+// Trailing newlines are trimmed from the file contents. The files are re-read on Reload.
+func (s *Set) EnableSecretFiles(names ...string) {
+	if s.secretFileFlags == nil {
+		s.secretFileFlags = make(map[string]bool)
+	}
+	for _, name := range names {
+		s.secretFileFlags[name] = true
+	}
+}
+
+// EnableSecretFilesForSecretFlags allows reading values for all the flags, which are recognized by IsSecretFlag, from files.
+//
+// See EnableSecretFiles for details.
+func (s *Set) EnableSecretFilesForSecretFlags() {
+	s.secretFilesForSecretFlags = true
+}
+
+// isSecretFileFlag returns true if the value for the flag with the given name may be read from file.
+func (s *Set) isSecretFileFlag(name string) bool {
+	if s.secretFileFlags[name] {
+		return true
+	}
+	return s.secretFilesForSecretFlags && s.IsSecretFlag(name)
+}
+
+// getSecretFileEnvName returns the name of env var with the path to the file with the value for the flag with the given name.
+//
+// An empty string is returned if the value for the flag cannot be read from file.
+func (s *Set) getSecretFileEnvName(name string) string {
+	if !s.isSecretFileFlag(name) {
+		return ""
+	}
+	return s.getEnvFlagName(name) + "_FILE"
+}
+
+// readSecretFile returns the contents of the file at path with trailing newlines trimmed.
+//
+// The returned error contains path, but never the file contents.
+func readSecretFile(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("missing path to the secret file")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveValue returns the actual value for the flag with the given name from value read from any source.
+//
+// `@/path/to/file` values are replaced with the file contents for flags registered via EnableSecretFiles,
+// `secretref://` values are resolved via resolvers registered with RegisterResolver,
+// while `enc:` values are decrypted with the key set via SetEncryptionKeyFile or SetEncryptionKeyEnv.
+func (s *Set) resolveValue(name, value string) (string, error) {
+	if path, ok := strings.CutPrefix(value, "@"); ok && s.isSecretFileFlag(name) {
+		return readSecretFile(path)
+	}
+	if strings.HasPrefix(value, secretRefPrefix) {
+		return s.resolveSecretRef(name, value)
+	}
+	if strings.HasPrefix(value, encryptedValuePrefix) {
+		return s.decryptValue(name, value)
+	}
+	return value, nil
+}
+
+// isReloadableRef returns true if value for the flag with the given name refers to the contents,
+// which may change between Reload calls, i.e. `secretref://` value or `@/path/to/file` value.
+func (s *Set) isReloadableRef(name, value string) bool {
+	if strings.HasPrefix(value, "@") && s.isSecretFileFlag(name) {
+		return true
+	}
+	return strings.HasPrefix(value, secretRefPrefix)
+}
+
+// resolvingValue passes values through Set.resolveValue before setting them to the wrapped flag.Value.
+//
+// It is used for the command-line flags, which are set by flag.FlagSet.Parse.
+type resolvingValue struct {
+	flag.Value
+
+	s    *Set
+	name string
+}
+
+// Set implements flag.Value interface
+func (rv *resolvingValue) Set(value string) error {
+	v, err := rv.s.resolveValue(rv.name, value)
+	if err != nil {
+		return err
+	}
+	if rv.s.isReloadableRef(rv.name, value) && !isArrayValue(rv.Value) {
+		// Remember the reference, so it is resolved again on Reload.
+		rv.s.setCommandLineSecretRef(rv.name, value)
+	}
+	return rv.Value.Set(v)
+}
+
+// IsBoolFlag implements flag.IsBoolFlag interface
+func (rv *resolvingValue) IsBoolFlag() bool {
+	bf, ok := rv.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+// wrapResolvingValues wraps flag values with resolvingValue, so command-line values are resolved by flag.FlagSet.Parse.
+//
+// The returned function restores the original values. It is also called before printing the usage,
+// since the usage relies on the original flag value types.
+func (s *Set) wrapResolvingValues() func() {
+	var wrapped []*flag.Flag
+	s.fs.VisitAll(func(f *flag.Flag) {
+		f.Value = &resolvingValue{
+			Value: f.Value,
+			s:     s,
+			name:  f.Name,
+		}
+		wrapped = append(wrapped, f)
+	})
+	usage := s.fs.Usage
+	restored := false
+	restore := func() {
+		if restored {
+			return
+		}
+		restored = true
+		for _, f := range wrapped {
+			f.Value = f.Value.(*resolvingValue).Value
+		}
+		s.fs.Usage = usage
+	}
+	s.fs.Usage = func() {
+		restore()
+		if usage != nil {
+			usage()
+			return
+		}
+		fmt.Fprintf(s.fs.Output(), "Usage of %s:\n", s.fs.Name())
+		s.fs.PrintDefaults()
+	}
+	return restore
+}
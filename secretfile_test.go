@@ -0,0 +1,177 @@
+package flagx
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretFilesCommandLine(t *testing.T) {
+	path := writeTestFile(t, "db", "s3cret\n\n")
+	s := NewSet("test", flag.ContinueOnError)
+	password := s.NewString("db.password", "", "")
+	user := s.NewString("db.user", "", "")
+	port := s.NewInt("db.port", 0, "")
+	s.EnableSecretFiles("db.password", "db.port")
+	portPath := writeTestFile(t, "port", "5432\r\n")
+	if err := s.Parse([]string{"-db.password=@" + path, "-db.user=@" + path, "-db.port", "@" + portPath}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if *password != "s3cret" {
+		t.Fatalf("unexpected password; got %q; want %q", *password, "s3cret")
+	}
+	// Values for flags not registered via EnableSecretFiles are used as is.
+	if *user != "@"+path {
+		t.Fatalf("unexpected user; got %q; want %q", *user, "@"+path)
+	}
+	if *port != 5432 {
+		t.Fatalf("unexpected port; got %d; want %d", *port, 5432)
+	}
+	// The original flag values must be restored after parsing.
+	if _, ok := s.FlagSet().Lookup("db.password").Value.(*resolvingValue); ok {
+		t.Fatalf("the flag value must be unwrapped after parsing")
+	}
+}
+
+func TestSecretFilesCommandLineFailure(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	s.NewString("db.password", "", "")
+	s.EnableSecretFiles("db.password")
+	s.FlagSet().SetOutput(io.Discard)
+	missingPath := filepath.Join(t.TempDir(), "missing")
+	err := s.Parse([]string{"-db.password=@" + missingPath})
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if !strings.Contains(err.Error(), missingPath) {
+		t.Fatalf("the error must contain the path to the file; got %q", err)
+	}
+	if _, ok := s.FlagSet().Lookup("db.password").Value.(*resolvingValue); ok {
+		t.Fatalf("the flag value must be unwrapped after the failed parsing")
+	}
+}
+
+func TestSecretFilesEnv(t *testing.T) {
+	path := writeTestFile(t, "token", "t0ken\n")
+	newSet := func() (*Set, *string, *string) {
+		s := NewSet("test", flag.ContinueOnError)
+		token := s.NewString("api.token", "", "")
+		name := s.NewString("api.name", "", "")
+		s.EnableSecretFilesForSecretFlags()
+		return s, token, name
+	}
+
+	t.Setenv("API_TOKEN_FILE", path)
+	t.Setenv("API_NAME_FILE", path)
+	s, token, name := newSet()
+	if err := s.Parse(nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if *token != "t0ken" {
+		t.Fatalf("unexpected token; got %q; want %q", *token, "t0ken")
+	}
+	if origin := s.Origin("api.token"); origin != "env API_TOKEN_FILE" {
+		t.Fatalf("unexpected origin; got %q; want %q", origin, "env API_TOKEN_FILE")
+	}
+	// api.name isn't a secret flag, so API_NAME_FILE must be ignored.
+	if *name != "" {
+		t.Fatalf("unexpected name; got %q; want empty value", *name)
+	}
+
+	// The env var without _FILE suffix has priority.
+	t.Setenv("API_TOKEN", "plain")
+	s, token, _ = newSet()
+	if err := s.Parse(nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if *token != "plain" {
+		t.Fatalf("unexpected token; got %q; want %q", *token, "plain")
+	}
+
+	// Errors must name the file, but not the secret.
+	missingPath := filepath.Join(t.TempDir(), "missing")
+	t.Setenv("API_TOKEN", "")
+	t.Setenv("API_TOKEN_FILE", missingPath)
+	s, _, _ = newSet()
+	err := s.Parse(nil)
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expecting *ParseError; got %v", err)
+	}
+	fes := pe.FlagErrors()
+	if len(fes) != 1 || fes[0].Source != "env API_TOKEN_FILE" {
+		t.Fatalf("unexpected flag errors: %v", fes)
+	}
+	if !strings.Contains(err.Error(), missingPath) {
+		t.Fatalf("the error must contain the path to the file; got %q", err)
+	}
+}
+
+func TestSecretFilesReload(t *testing.T) {
+	secretPath := writeTestFile(t, "secret", "first\n")
+	configPath := writeTestFile(t, "config.yaml", "auth:\n  secret: '@"+secretPath+"'\n")
+	s := NewSet("test", flag.ContinueOnError)
+	secret := s.NewString("auth.secret", "", "")
+	s.EnableSecretFiles("auth.secret")
+	s.MarkReloadable("auth.secret")
+	if err := s.ParseWithFile(nil, configPath); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if *secret != "first" {
+		t.Fatalf("unexpected secret; got %q; want %q", *secret, "first")
+	}
+
+	// The secret file must be re-read on reload.
+	if err := os.WriteFile(secretPath, []byte("second\n"), 0o600); err != nil {
+		t.Fatalf("cannot update secret file: %s", err)
+	}
+	if err := s.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if *secret != "second" {
+		t.Fatalf("unexpected secret after reload; got %q; want %q", *secret, "second")
+	}
+
+	// The previous value must remain active if the file cannot be read.
+	if err := os.Remove(secretPath); err != nil {
+		t.Fatalf("cannot remove secret file: %s", err)
+	}
+	if err := s.Reload(); err == nil {
+		t.Fatalf("expecting non-nil error for missing secret file")
+	}
+	if *secret != "second" {
+		t.Fatalf("unexpected secret after the failed reload; got %q; want %q", *secret, "second")
+	}
+}
+
+func TestSecretFilesReloadCommandLine(t *testing.T) {
+	secretPath := writeTestFile(t, "secret", "first\n")
+	s := NewSet("test", flag.ContinueOnError)
+	secret := s.NewString("auth.secret", "", "")
+	s.EnableSecretFiles("auth.secret")
+	s.MarkReloadable("auth.secret")
+	if err := s.Parse([]string{"-auth.secret=@" + secretPath}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if *secret != "first" {
+		t.Fatalf("unexpected secret; got %q; want %q", *secret, "first")
+	}
+
+	// The secret file passed via command line must be re-read on reload.
+	if err := os.WriteFile(secretPath, []byte("second\n"), 0o600); err != nil {
+		t.Fatalf("cannot update secret file: %s", err)
+	}
+	if err := s.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if *secret != "second" {
+		t.Fatalf("unexpected secret after reload; got %q; want %q", *secret, "second")
+	}
+	if origin := s.Origin("auth.secret"); origin != OriginCommandLine {
+		t.Fatalf("unexpected origin; got %q; want %q", origin, OriginCommandLine)
+	}
+}
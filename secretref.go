@@ -0,0 +1,202 @@
+package flagx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// secretRefPrefix is the prefix for flag values, which must be resolved via resolvers registered with RegisterResolver.
+const secretRefPrefix = "secretref://"
+
+// Resolver returns the secret value for the given ref.
+//
+// ref is the part of `secretref://scheme/ref` flag value after the `scheme/`.
+// ctx is canceled when the timeout set via SetResolveTimeout expires.
+type Resolver func(ctx context.Context, ref string) (string, error)
+
+// resolvers contains Resolver functions keyed by scheme.
+var resolvers sync.Map
+
+// RegisterResolver registers fn for resolving `secretref://scheme/ref` flag values.
+//
+// Flag values from all the sources, including command line, are resolved at Parse* time and on Reload,
+// so secrets don't appear in the process command line, env vars and config files.
+// Flags with resolved values are recognized as secret by IsSecretFlag.
+//
+// The following resolvers are registered out of the box:
+//
+//   - file reads the file at absolute path, i.e. `secretref://file/run/creds/db` reads `/run/creds/db`;
+//   - env reads the env var, i.e. `secretref://env/DB_PASSWORD`.
+//
+// The exec resolver is registered via EnableExecResolver.
+//
+// Trailing newlines are trimmed from the values returned by the built-in resolvers.
+//
+// RegisterResolver overrides the previously registered resolver for the scheme.
+func RegisterResolver(scheme string, fn Resolver) {
+	resolvers.Store(scheme, fn)
+}
+
+func getResolver(scheme string) (Resolver, error) {
+	fn, ok := resolvers.Load(scheme)
+	if !ok {
+		return nil, fmt.Errorf("unsupported secret reference scheme %q; register it via RegisterResolver", scheme)
+	}
+	return fn.(Resolver), nil
+}
+
+var resolveTimeout atomic.Int64
+
+// SetResolveTimeout sets the timeout for resolving a single `secretref://` flag value.
+//
+// The default timeout is 10 seconds.
+func SetResolveTimeout(d time.Duration) {
+	resolveTimeout.Store(int64(d))
+}
+
+func getResolveTimeout() time.Duration {
+	if d := resolveTimeout.Load(); d > 0 {
+		return time.Duration(d)
+	}
+	return 10 * time.Second
+}
+
+func init() {
+	RegisterResolver("file", func(_ context.Context, ref string) (string, error) {
+		return readSecretFile("/" + ref)
+	})
+	RegisterResolver("env", func(_ context.Context, ref string) (string, error) {
+		v, ok := os.LookupEnv(ref)
+		if !ok {
+			return "", fmt.Errorf("missing env var %q", ref)
+		}
+		return v, nil
+	})
+}
+
+// EnableExecResolver registers the exec resolver for `secretref://exec/...` flag values.
+//
+// The resolver runs the command at absolute path with space-separated args and reads its output,
+// i.e. `secretref://exec/usr/local/bin/get-secret db` runs `/usr/local/bin/get-secret db`.
+//
+// It isn't registered by default, since it allows running arbitrary commands
+// by anyone who controls the command line, env vars or config files.
+func EnableExecResolver() {
+	RegisterResolver("exec", resolveExec)
+}
+
+func resolveExec(ctx context.Context, ref string) (string, error) {
+	args := strings.Fields(ref)
+	if len(args) == 0 {
+		return "", fmt.Errorf("missing command")
+	}
+	cmd := exec.CommandContext(ctx, "/"+args[0], args[1:]...)
+	// Discard stderr, since it may contain the secret.
+	cmd.Stderr = io.Discard
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("cannot run command %q in %s: %w", cmd.Path, getResolveTimeout(), ctx.Err())
+		}
+		return "", fmt.Errorf("cannot run command %q: %w", cmd.Path, err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// secretRefs holds resolved `secretref://` values for Set.
+type secretRefs struct {
+	mu sync.RWMutex
+
+	// cache contains resolved values keyed by references. It is reset on Reload.
+	cache map[string]string
+
+	// flags contains names of flags with resolved values.
+	flags map[string]bool
+
+	// commandLine contains references passed via command line keyed by flag name,
+	// so they are resolved again on Reload.
+	commandLine map[string]string
+}
+
+// resolveSecretRef returns the value for the flag with the given name and `secretref://` value.
+func (s *Set) resolveSecretRef(name, value string) (string, error) {
+	sr := s.secretRefs
+	sr.mu.RLock()
+	v, ok := sr.cache[value]
+	sr.mu.RUnlock()
+	if !ok {
+		var err error
+		v, err = resolveSecretRef(value)
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve secret reference %q: %w", value, err)
+		}
+	}
+
+	sr.mu.Lock()
+	if sr.cache == nil {
+		sr.cache = make(map[string]string)
+	}
+	if sr.flags == nil {
+		sr.flags = make(map[string]bool)
+	}
+	sr.cache[value] = v
+	sr.flags[name] = true
+	sr.mu.Unlock()
+	return v, nil
+}
+
+func resolveSecretRef(value string) (string, error) {
+	scheme, ref, _ := strings.Cut(strings.TrimPrefix(value, secretRefPrefix), "/")
+	fn, err := getResolver(scheme)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), getResolveTimeout())
+	defer cancel()
+	return fn(ctx, ref)
+}
+
+// isResolvedSecretFlag returns true if the flag with the given name has a value resolved from `secretref://` reference.
+func (s *Set) isResolvedSecretFlag(name string) bool {
+	sr := s.secretRefs
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	return sr.flags[name]
+}
+
+// setCommandLineSecretRef remembers the `secretref://` or `@/path/to/file` value passed via command line for the flag with the given name.
+func (s *Set) setCommandLineSecretRef(name, value string) {
+	sr := s.secretRefs
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if sr.commandLine == nil {
+		sr.commandLine = make(map[string]string)
+	}
+	sr.commandLine[name] = value
+}
+
+// getCommandLineSecretRef returns the `secretref://` or `@/path/to/file` value passed via command line for the flag with the given name.
+func (s *Set) getCommandLineSecretRef(name string) (string, bool) {
+	sr := s.secretRefs
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	v, ok := sr.commandLine[name]
+	return v, ok
+}
+
+// resetSecretRefsCache drops the cached values, so references are resolved again.
+//
+// Flags with the previously resolved values remain secret.
+func (s *Set) resetSecretRefsCache() {
+	sr := s.secretRefs
+	sr.mu.Lock()
+	sr.cache = nil
+	sr.mu.Unlock()
+}
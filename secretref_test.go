@@ -0,0 +1,156 @@
+package flagx
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSecretRefBuiltinResolvers(t *testing.T) {
+	EnableExecResolver()
+	defer resolvers.Delete("exec")
+	path := writeTestFile(t, "db", "from-file\n")
+	t.Setenv("TEST_SECRET_REF", "from-env")
+	s := NewSet("test", flag.ContinueOnError)
+	fromFile := s.NewString("db.password", "", "")
+	fromEnv := s.NewString("api.auth", "", "")
+	fromExec := s.NewString("exec.value", "", "")
+	plain := s.NewString("plain", "", "")
+	args := []string{
+		"-db.password=secretref://file" + path,
+		"-api.auth=secretref://env/TEST_SECRET_REF",
+		"-exec.value=secretref://exec/bin/echo from-exec",
+		"-plain=foo",
+	}
+	if err := s.Parse(args); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f := func(name string, result *string, expected string) {
+		t.Helper()
+		if *result != expected {
+			t.Fatalf("unexpected value for %s; got %q; want %q", name, *result, expected)
+		}
+		if !s.IsSecretFlag(name) {
+			t.Fatalf("flag %s with resolved value must be secret", name)
+		}
+	}
+	f("db.password", fromFile, "from-file")
+	f("api.auth", fromEnv, "from-env")
+	f("exec.value", fromExec, "from-exec")
+	if s.IsSecretFlag("plain") {
+		t.Fatalf("flag plain mustn't be secret")
+	}
+	if *plain != "foo" {
+		t.Fatalf("unexpected value for plain; got %q; want %q", *plain, "foo")
+	}
+}
+
+func TestSecretRefFailure(t *testing.T) {
+	f := func(value, expected string) {
+		t.Helper()
+		s := NewSet("test", flag.ContinueOnError)
+		s.NewString("value", "", "")
+		s.FlagSet().SetOutput(io.Discard)
+		err := s.Parse([]string{"-value=" + value})
+		if err == nil {
+			t.Fatalf("expecting non-nil error for %q", value)
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("unexpected error for %q; got %q; want it to contain %q", value, err, expected)
+		}
+	}
+	f("secretref://unknown/foo", `unsupported secret reference scheme "unknown"`)
+	f("secretref://env/TEST_MISSING_SECRET_REF", `missing env var "TEST_MISSING_SECRET_REF"`)
+	f("secretref://file/non/existing/path", "/non/existing/path")
+	f("secretref://exec/bin/echo foo", `unsupported secret reference scheme "exec"`)
+
+	EnableExecResolver()
+	defer resolvers.Delete("exec")
+	f("secretref://exec/bin/false", `cannot run command "/bin/false"`)
+
+	SetResolveTimeout(100 * time.Millisecond)
+	defer SetResolveTimeout(0)
+	f("secretref://exec/bin/sleep 10", "context deadline exceeded")
+}
+
+func TestSecretRefCustomResolver(t *testing.T) {
+	var calls atomic.Int64
+	RegisterResolver("test-counter", func(_ context.Context, ref string) (string, error) {
+		n := calls.Add(1)
+		return fmt.Sprintf("%s-%d", ref, n), nil
+	})
+	path := writeTestFile(t, "config.yaml", "replica:\n  password: secretref://test-counter/config\n")
+	s := NewSet("test", flag.ContinueOnError)
+	primary := s.NewString("primary.password", "", "")
+	replica := s.NewString("replica.password", "", "")
+	backup := s.NewString("backup.password", "", "")
+	s.MarkReloadable("primary.password", "replica.password")
+	args := []string{"-primary.password=secretref://test-counter/db", "-backup.password=secretref://test-counter/db"}
+	if err := s.ParseWithFile(args, path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f := func(name string, result *string, expected string) {
+		t.Helper()
+		if *result != expected {
+			t.Fatalf("unexpected value for %s; got %q; want %q", name, *result, expected)
+		}
+	}
+	// The same reference must be resolved once.
+	f("primary.password", primary, "db-1")
+	f("backup.password", backup, "db-1")
+	f("replica.password", replica, "config-2")
+
+	// Reloadable flags must be resolved again on reload, including flags set via command line.
+	if err := s.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f("primary.password", primary, "db-3")
+	f("backup.password", backup, "db-1")
+	f("replica.password", replica, "config-4")
+	if origin := s.Origin("primary.password"); origin != OriginCommandLine {
+		t.Fatalf("unexpected origin; got %q; want %q", origin, OriginCommandLine)
+	}
+}
+
+func TestSecretRefReloadDoesntBlockReaders(t *testing.T) {
+	var block atomic.Bool
+	resolving := make(chan struct{})
+	unblock := make(chan struct{})
+	RegisterResolver("test-blocking", func(_ context.Context, ref string) (string, error) {
+		if block.Load() {
+			close(resolving)
+			<-unblock
+		}
+		return ref, nil
+	})
+	path := writeTestFile(t, "config.yaml", "password: secretref://test-blocking/foo\n")
+	s := NewSet("test", flag.ContinueOnError)
+	password := s.NewString("password", "", "")
+	s.MarkReloadable("password")
+	if err := s.ParseWithFile(nil, path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	block.Store(true)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.Reload()
+	}()
+	<-resolving
+	// Readers mustn't wait for the resolver.
+	if origin := s.Origin("password"); origin == OriginDefault {
+		t.Fatalf("unexpected origin during reload; got %q", origin)
+	}
+	close(unblock)
+	if err := <-errCh; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if *password != "foo" {
+		t.Fatalf("unexpected value; got %q; want %q", *password, "foo")
+	}
+}
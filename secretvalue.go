@@ -0,0 +1,109 @@
+package flagx
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+)
+
+// NewSecret returns new `secret` flag with the given name and description.
+func NewSecret(name, description string) *Secret {
+	return CommandLine.NewSecret(name, description)
+}
+
+// NewSecret returns new `secret` flag with the given name and description.
+//
+// The flag is registered via RegisterSecretFlag, so its value is redacted everywhere.
+func (s *Set) NewSecret(name, description string) *Secret {
+	sv := &Secret{}
+	s.fs.Var(sv, name, description)
+	s.RegisterSecretFlag(name)
+	return sv
+}
+
+// Secret is a flag for holding a secret value such as a password or an API token.
+//
+// The value is never returned by String, fmt and slog, so it cannot leak via flag.PrintDefaults,
+// logs or error messages. Use Reveal for obtaining the value.
+type Secret struct {
+	value atomic.Pointer[[]byte]
+}
+
+// Reveal returns the stored value.
+//
+// It is safe calling Reveal concurrently with Set.
+func (sv *Secret) Reveal() string {
+	if p := sv.value.Load(); p != nil {
+		return string(*p)
+	}
+	return ""
+}
+
+// IsSet returns true if the value is set.
+func (sv *Secret) IsSet() bool {
+	return sv.value.Load() != nil
+}
+
+// String implements flag.Value interface
+//
+// It returns "secret" if the value is set and an empty string otherwise.
+func (sv *Secret) String() string {
+	if !sv.IsSet() {
+		return ""
+	}
+	return secretMarker
+}
+
+// Set implements flag.Value interface
+func (sv *Secret) Set(value string) error {
+	if value == "" {
+		sv.value.Store(nil)
+		return nil
+	}
+	b := []byte(value)
+	sv.value.Store(&b)
+	return nil
+}
+
+// Format implements fmt.Formatter interface
+//
+// It writes the same value as String for all the verbs.
+func (sv *Secret) Format(f fmt.State, verb rune) {
+	s := sv.String()
+	if verb == 'q' {
+		s = strconv.Quote(s)
+	}
+	_, _ = io.WriteString(f, s)
+}
+
+// LogValue implements slog.LogValuer interface
+//
+// It returns the same value as String.
+func (sv *Secret) LogValue() slog.Value {
+	return slog.StringValue(sv.String())
+}
+
+// Close clears the stored value and overwrites its buffer with zeros.
+//
+// Strings returned by Reveal aren't cleared, so the caller must take care of not keeping them around.
+// Close mustn't be called concurrently with Reveal.
+func (sv *Secret) Close() error {
+	p := sv.value.Swap(nil)
+	if p != nil {
+		clear(*p)
+	}
+	return nil
+}
+
+// rawValue returns the actual value of v, which may be passed back to v.Set.
+//
+// It differs from v.String only for Secret flags.
+func rawValue(v flag.Value) string {
+	if sv, ok := v.(*Secret); ok {
+		return sv.Reveal()
+	}
+	return v.String()
+}
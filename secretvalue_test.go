@@ -0,0 +1,105 @@
+package flagx
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSecretRedacted(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	sv := s.NewSecret("db.dsn", "the database dsn")
+	if sv.String() != "" {
+		t.Fatalf("unexpected value for unset secret; got %q; want empty value", sv.String())
+	}
+	if err := s.Parse([]string{"-db.dsn=hunter2"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v := sv.Reveal(); v != "hunter2" {
+		t.Fatalf("unexpected revealed value; got %q; want %q", v, "hunter2")
+	}
+
+	var bb bytes.Buffer
+	check := func(what, result string) {
+		t.Helper()
+		if strings.Contains(result, "hunter2") {
+			t.Fatalf("the secret leaked via %s: %q", what, result)
+		}
+	}
+	check("String", sv.String())
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x"} {
+		check(format, fmt.Sprintf(format, sv))
+	}
+	if result, expected := fmt.Sprintf("%v %q", sv, sv), `secret "secret"`; result != expected {
+		t.Fatalf("unexpected formatted value; got %q; want %q", result, expected)
+	}
+
+	logger := slog.New(slog.NewTextHandler(&bb, nil))
+	logger.Info("connecting", "dsn", sv)
+	check("slog", bb.String())
+	if !strings.Contains(bb.String(), "dsn=secret") {
+		t.Fatalf("unexpected log output: %q", bb.String())
+	}
+
+	bb.Reset()
+	s.WriteFlags(&bb)
+	check("WriteFlags", bb.String())
+	bb.Reset()
+	s.FlagSet().SetOutput(&bb)
+	s.FlagSet().PrintDefaults()
+	check("PrintDefaults", bb.String())
+	for _, fi := range s.Flags() {
+		check("Flags", fi.Value)
+	}
+	if !s.IsSecretFlag("db.dsn") {
+		t.Fatalf("the flag must be registered as secret")
+	}
+}
+
+func TestSecretClose(t *testing.T) {
+	var sv Secret
+	if err := sv.Set("hunter2"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	buf := *sv.value.Load()
+	if err := sv.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(buf, make([]byte, len(buf))) {
+		t.Fatalf("the buffer must be zeroed; got %q", buf)
+	}
+	if sv.IsSet() || sv.Reveal() != "" || sv.String() != "" {
+		t.Fatalf("the secret must be cleared after Close")
+	}
+}
+
+func TestSecretReload(t *testing.T) {
+	path := writeTestFile(t, "config.yaml", "api:\n  token: first\n")
+	s := NewSet("test", flag.ContinueOnError)
+	sv := s.NewSecret("api.token", "")
+	s.MarkReloadable("api.token")
+	if err := s.ParseWithFile(nil, path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var changes []string
+	s.OnChange(func(name, oldValue, newValue string) {
+		changes = append(changes, name+":"+oldValue+":"+newValue)
+	})
+
+	if err := os.WriteFile(path, []byte("api:\n  token: second\n"), 0o600); err != nil {
+		t.Fatalf("cannot update config file: %s", err)
+	}
+	if err := s.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v := sv.Reveal(); v != "second" {
+		t.Fatalf("unexpected value after reload; got %q; want %q", v, "second")
+	}
+	if len(changes) != 1 || changes[0] != "api.token:secret:secret" {
+		t.Fatalf("unexpected changes: %q", changes)
+	}
+}
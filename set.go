@@ -0,0 +1,481 @@
+package flagx
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Set is a set of flags together with its own env prefix, secret flags, sources and flag origins.
+//
+// Use NewSet for building configurations isolated from the global CommandLine,
+// i.e. in libraries and tests.
+//
+// Set methods cannot be called from concurrent goroutines,
+// except of Origin and the reload-related methods.
+type Set struct {
+	fs *flag.FlagSet
+
+	envPrefix  *string
+	configFile *string
+
+	// envFlagNames contains environment variable names explicitly set for flags via `env` struct tag.
+	envFlagNames map[string]string
+
+	// secretFlags contains lowercased names of flags registered via RegisterSecretFlag.
+	secretFlags map[string]bool
+
+	// nonSecretFlags contains lowercased names of flags registered via RegisterNonSecretFlag.
+	nonSecretFlags map[string]bool
+
+	// secretPatterns contains patterns for names of secret flags. defaultSecretPatterns are used if it is nil.
+	secretPatterns []*regexp.Regexp
+
+	// noSecretValueDetection is set to true by DisableSecretValueDetection.
+	noSecretValueDetection bool
+
+	// sources contains additional sources registered via AddSource.
+	sources []Source
+
+	// fileFlags contains names of flags registered via MarkFileFlag.
+	fileFlags map[string]bool
+
+	// secretFileFlags contains names of flags registered via EnableSecretFiles.
+	secretFileFlags map[string]bool
+
+	// secretFilesForSecretFlags is set to true by EnableSecretFilesForSecretFlags.
+	secretFilesForSecretFlags bool
+
+	// secretRefs holds values resolved from `secretref://` references.
+	secretRefs *secretRefs
+
+	// encryptionKeyFile is the path set via SetEncryptionKeyFile.
+	encryptionKeyFile string
+
+	// encryptionKeyEnv is the env var name set via SetEncryptionKeyEnv.
+	encryptionKeyEnv string
+
+	// encrypted holds the state for decrypting `enc:` values.
+	encrypted *encryptedValues
+
+	// constraints contains constraints registered via Required, OneOf, MutuallyExclusive and RequiresIf.
+	constraints []constraint
+
+	// helpFilter is the filter passed via `-help=filter` to the last Parse* call.
+	helpFilter string
+
+	// reloadMu serializes Reload calls.
+	reloadMu *sync.Mutex
+
+	// mu protects the fields below, which may be accessed by Reload from concurrent goroutines.
+	mu *sync.Mutex
+
+	// origins contains origins for the parsed flags keyed by flag name.
+	origins map[string]string
+
+	// parsedSources contains sources used by the last Parse* call.
+	parsedSources []Source
+
+	// reloadable contains names of flags, which may be updated by Reload.
+	reloadable map[string]bool
+
+	// onChange contains callbacks registered via OnChange.
+	onChange []func(name, oldValue, newValue string)
+}
+
+// CommandLine is the default Set backed by flag.CommandLine.
+//
+// Top-level functions of this package operate on CommandLine.
+var CommandLine = newSet(flag.CommandLine)
+
+// NewSet returns new Set with the given name and error handling policy.
+//
+// See flag.NewFlagSet for details.
+func NewSet(name string, errorHandling flag.ErrorHandling) *Set {
+	return newSet(flag.NewFlagSet(name, errorHandling))
+}
+
+func newSet(fs *flag.FlagSet) *Set {
+	s := &Set{
+		fs:           fs,
+		envFlagNames: make(map[string]string),
+		secretFlags:  make(map[string]bool),
+		secretRefs:   &secretRefs{},
+		encrypted:    &encryptedValues{},
+		reloadMu:     &sync.Mutex{},
+		mu:           &sync.Mutex{},
+		origins:      make(map[string]string),
+	}
+	if fs == flag.CommandLine {
+		flag.Usage = s.printUsage
+	} else {
+		fs.Usage = s.printUsage
+	}
+	s.envPrefix = fs.String("env.prefix", "", "Prefix for environment variables.")
+	s.configFile = fs.String("config.file", "", "Path to YAML, TOML or JSON config file with flag values. The format is detected by the file extension. "+
+		"Nested keys map onto dotted flag names, i.e. 'server: {port: 80}' sets -server.port. "+
+		"Values from the file have lower priority than environment vars and command-line flags.")
+	return s
+}
+
+// flagSetSets contains Set instances for flag sets passed to ForFlagSet, ParseFlagSet* and BindFlagSet keyed by *flag.FlagSet.
+//
+// The entries live for the whole process lifetime, since they are never deleted.
+// Use NewSet for short-lived flag sets.
+var flagSetSets sync.Map
+
+// ForFlagSet returns Set used by ParseFlagSet* and BindFlagSet for fs.
+//
+// It may be used for registering constraints for flags in fs and for obtaining their origins.
+// The returned Set has its own origins and constraints, while env prefix, secret rules and secret files
+// are taken from CommandLine on every ParseFlagSet* and BindFlagSet call.
+//
+// The returned Set is kept for the whole process lifetime, so use NewSet for short-lived flag sets.
+func ForFlagSet(fs *flag.FlagSet) *Set {
+	if fs == CommandLine.fs {
+		return CommandLine
+	}
+	v, ok := flagSetSets.Load(fs)
+	if !ok {
+		v, _ = flagSetSets.LoadOrStore(fs, &Set{
+			fs:           fs,
+			envFlagNames: make(map[string]string),
+			secretRefs:   CommandLine.secretRefs,
+			encrypted:    CommandLine.encrypted,
+			reloadMu:     &sync.Mutex{},
+			mu:           &sync.Mutex{},
+			origins:      make(map[string]string),
+		})
+	}
+	return v.(*Set)
+}
+
+// setFor returns Set for parsing fs.
+//
+// Flag sets not created via NewSet have their own env var names set via `env` struct tag in BindFlagSet,
+// origins and constraints. They share env prefix, secret rules, secret files and resolved secrets with CommandLine.
+func setFor(fs *flag.FlagSet) *Set {
+	s := ForFlagSet(fs)
+	if s == CommandLine {
+		return s
+	}
+
+	// Refresh the settings shared with CommandLine, since they may be changed after the previous call.
+	s.envPrefix = CommandLine.envPrefix
+	s.secretFlags = CommandLine.secretFlags
+	s.nonSecretFlags = CommandLine.nonSecretFlags
+	s.secretPatterns = CommandLine.secretPatterns
+	s.noSecretValueDetection = CommandLine.noSecretValueDetection
+	s.secretFileFlags = CommandLine.secretFileFlags
+	s.secretFilesForSecretFlags = CommandLine.secretFilesForSecretFlags
+	s.encryptionKeyFile = CommandLine.encryptionKeyFile
+	s.encryptionKeyEnv = CommandLine.encryptionKeyEnv
+	return s
+}
+
+// FlagSet returns the underlying flag set.
+func (s *Set) FlagSet() *flag.FlagSet {
+	return s.fs
+}
+
+// Var defines a flag with the given name, description and value.
+func (s *Set) Var(value flag.Value, name, description string) {
+	s.fs.Var(value, name, description)
+}
+
+// NewBool returns new bool flag with the given name, defaultValue and description.
+func (s *Set) NewBool(name string, defaultValue bool, description string) *bool {
+	return s.fs.Bool(name, defaultValue, description)
+}
+
+// NewString returns new string flag with the given name, defaultValue and description.
+func (s *Set) NewString(name string, defaultValue string, description string) *string {
+	return s.fs.String(name, defaultValue, description)
+}
+
+// NewInt returns new int flag with the given name, defaultValue, description and optional opts.
+//
+// Min, Max and Validate[int64] options are supported.
+func (s *Set) NewInt(name string, defaultValue int, description string, opts ...Option) *int {
+	if len(opts) == 0 {
+		return s.fs.Int(name, defaultValue, description)
+	}
+	p := new(int)
+	*p = defaultValue
+	s.newIntVar(name, description, opts, false, func() int64 { return int64(*p) }, func(n int64) { *p = int(n) })
+	return p
+}
+
+// NewInt64 returns new int64 flag with the given name, defaultValue, description and optional opts.
+//
+// Min, Max and Validate[int64] options are supported.
+func (s *Set) NewInt64(name string, defaultValue int64, description string, opts ...Option) *int64 {
+	if len(opts) == 0 {
+		return s.fs.Int64(name, defaultValue, description)
+	}
+	p := new(int64)
+	*p = defaultValue
+	s.newIntVar(name, description, opts, true, func() int64 { return *p }, func(n int64) { *p = n })
+	return p
+}
+
+func (s *Set) newIntVar(name, description string, opts []Option, is64 bool, load func() int64, store func(n int64)) {
+	validators, help := applyOptions(opts).intChecks(name, func(n int64) string {
+		return strconv.FormatInt(n, 10)
+	})
+	if err := validateInt(validators, load()); err != nil {
+		panic(fmt.Sprintf("BUG: invalid default value %d for flag %s: %s", load(), name, err))
+	}
+	s.fs.Var(&intValue{
+		is64:       is64,
+		load:       load,
+		store:      store,
+		validators: validators,
+	}, name, description+help)
+}
+
+// intValue is int or int64 flag value with validators.
+type intValue struct {
+	// is64 is set to true for int64 flags.
+	is64       bool
+	load       func() int64
+	store      func(n int64)
+	validators []func(n int64) error
+}
+
+// String implements flag.Value interface
+func (iv *intValue) String() string {
+	if iv.load == nil {
+		// Called by flag.PrintDefaults on zero value.
+		return "0"
+	}
+	return strconv.FormatInt(iv.load(), 10)
+}
+
+// Set implements flag.Value interface
+func (iv *intValue) Set(value string) error {
+	bitSize := strconv.IntSize
+	if iv.is64 {
+		bitSize = 64
+	}
+	n, err := strconv.ParseInt(value, 0, bitSize)
+	if err != nil {
+		return err
+	}
+	if err := validateInt(iv.validators, n); err != nil {
+		return err
+	}
+	iv.store(n)
+	return nil
+}
+
+// Get implements flag.Getter interface
+func (iv *intValue) Get() any {
+	if iv.is64 {
+		return iv.load()
+	}
+	return int(iv.load())
+}
+
+// NewFloat returns new float64 flag with the given name, defaultValue and description.
+func (s *Set) NewFloat(name string, defaultValue float64, description string) *float64 {
+	return s.fs.Float64(name, defaultValue, description)
+}
+
+// AddSource adds src to the sources consulted by Parse.
+//
+// Sources added via AddSource have lower priority than environment vars and the config file
+// passed via -config.file. They are consulted in the order they were added.
+func (s *Set) AddSource(src Source) {
+	s.sources = append(s.sources, src)
+}
+
+// Parse parses the given args, environment vars, config file and sources added via AddSource.
+//
+// Flags set via command-line override flags set via environment vars,
+// which override flags set via config file passed to -config.file.
+//
+// The returned error is *ParseError holding all the invalid values,
+// unless it is flag.ErrHelp returned when -h or -help is passed in args.
+func (s *Set) Parse(args []string) error {
+	return s.ParseWithFile(args, "")
+}
+
+// ParseWithFile is like Parse, but reads flag values from the config file at path if -config.file isn't set.
+func (s *Set) ParseWithFile(args []string, path string) error {
+	if err := s.parseArgs(args); err != nil {
+		return err
+	}
+	var errs []error
+	sources := []Source{s.EnvSource()}
+	if path = s.getConfigFilePath(path); path != "" {
+		src, err := NewFileSource(path)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			sources = append(sources, src)
+		}
+	}
+	sources = append(sources, s.sources...)
+	errs = s.applySources(sources, errs)
+	return newParseError(errs)
+}
+
+// ParseWithSources parses the given args and reads the missing flag values from the given sources.
+//
+// Sources are consulted in the given order, so the first source has the highest priority.
+// Environment vars aren't read unless EnvSource is passed in sources.
+func (s *Set) ParseWithSources(args []string, sources ...Source) error {
+	if err := s.parseArgs(args); err != nil {
+		return err
+	}
+	return newParseError(s.applySources(sources, nil))
+}
+
+func (s *Set) parseArgs(args []string) error {
+	s.helpFilter = getHelpFilter(args)
+	restoreValues := s.wrapResolvingValues()
+	err := s.fs.Parse(args)
+	restoreValues()
+	if err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return &ParseError{
+			Errors: []error{fmt.Errorf("cannot parse command-line flags: %w", err)},
+		}
+	}
+	s.resetOrigins()
+	return nil
+}
+
+// applySources sets the flags, which weren't set via command-line, to values from sources.
+//
+// It appends the occurred errors to errs and returns the result.
+func (s *Set) applySources(sources []Source, errs []error) []error {
+	s.mu.Lock()
+	s.parsedSources = sources
+	s.mu.Unlock()
+
+	for _, src := range sources {
+		if c, ok := src.(flagSetChecker); ok {
+			if err := c.Check(s.fs); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	// Remember explicitly set command-line flags.
+	flagsSet := make(map[string]bool)
+	s.fs.Visit(func(f *flag.Flag) {
+		flagsSet[f.Name] = true
+	})
+
+	// Obtain the remaining flag values from sources.
+	s.fs.VisitAll(func(f *flag.Flag) {
+		if flagsSet[f.Name] {
+			// The flag is explicitly set via command-line.
+			return
+		}
+		for _, src := range sources {
+			v, ok := src.Lookup(f.Name)
+			if !ok {
+				continue
+			}
+			rv, err := s.resolveValue(f.Name, v)
+			if err == nil {
+				err = s.fs.Set(f.Name, rv)
+			}
+			if err != nil {
+				errs = append(errs, &FlagError{
+					Name:   f.Name,
+					Source: src.Provenance(f.Name),
+					Value:  v,
+					Err:    err,
+					secret: s.IsSecretFlag(f.Name),
+					value:  f.Value,
+				})
+				return
+			}
+			s.setOrigin(f.Name, src.Provenance(f.Name))
+			return
+		}
+	})
+	return s.checkConstraints(errs, s.Origin)
+}
+
+// getConfigFilePath returns the path to the config file.
+//
+// The path set via -config.file flag or the corresponding env var has priority over defaultPath.
+func (s *Set) getConfigFilePath(defaultPath string) string {
+	f := s.fs.Lookup("config.file")
+	if f == nil {
+		return defaultPath
+	}
+	isSet := false
+	s.fs.Visit(func(f *flag.Flag) {
+		if f.Name == "config.file" {
+			isSet = true
+		}
+	})
+	if isSet {
+		return f.Value.String()
+	}
+	if v := os.Getenv(s.getEnvFlagName(f.Name)); v != "" {
+		return v
+	}
+	if v := f.Value.String(); v != "" {
+		return v
+	}
+	return defaultPath
+}
+
+// WriteFlags writes all the explicitly set flags to w together with their origin.
+func (s *Set) WriteFlags(w io.Writer) {
+	s.visitSet(func(f *flag.Flag) {
+		value := redactValue(f.Value, f.Value.String())
+		if s.IsSecretFlag(f.Name) {
+			value = secretMarker
+		}
+		fmt.Fprintf(w, "-%s=%q (from %s)\n", f.Name, value, s.Origin(f.Name))
+	})
+}
+
+// Visit all the flag name and values
+func (s *Set) Visit(fn func(string, string)) {
+	s.VisitWithOrigin(func(name, value, _ string) {
+		fn(name, value)
+	})
+}
+
+// VisitWithOrigin is like Visit, but passes the origin of every flag value to fn.
+//
+// See Origin for details.
+func (s *Set) VisitWithOrigin(fn func(name, value, origin string)) {
+	s.visitSet(func(f *flag.Flag) {
+		lname := strings.ToLower(f.Name)
+		value := redactValue(f.Value, f.Value.String())
+		if s.IsSecretFlag(f.Name) {
+			value = secretMarker
+		}
+		fn(lname, value, s.Origin(f.Name))
+	})
+}
+
+func (s *Set) getEnvFlagName(name string) string {
+	if envName, ok := s.envFlagNames[name]; ok {
+		return envName
+	}
+	// Substitute dots with underscores, since env var names cannot contain dots.
+	// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/311#issuecomment-586354129 for details.
+	prefix := ""
+	if s.envPrefix != nil {
+		prefix = *s.envPrefix
+	}
+	return strings.ToUpper(prefix + strings.ReplaceAll(name, ".", "_"))
+}
@@ -0,0 +1,103 @@
+package flagx
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+)
+
+func TestSetIsolation(t *testing.T) {
+	s1 := NewSet("s1", flag.ContinueOnError)
+	s2 := NewSet("s2", flag.ContinueOnError)
+	addr1 := s1.NewString("server.addr", ":80", "")
+	addr2 := s2.NewString("server.addr", ":80", "")
+	limit := s1.NewBytes("limit", 1024, "")
+	s2.RegisterSecretFlag("server.addr")
+
+	t.Setenv("S1_SERVER_ADDR", ":81")
+	t.Setenv("SERVER_ADDR", ":82")
+	if err := s1.Parse([]string{"-env.prefix=s1_", "-limit=1MiB"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := s2.Parse(nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if *addr1 != ":81" || *addr2 != ":82" {
+		t.Fatalf("unexpected values; got %q, %q", *addr1, *addr2)
+	}
+	if limit.Load() != 1024*1024 {
+		t.Fatalf("unexpected limit; got %d", limit.Load())
+	}
+	if origin := s1.Origin("server.addr"); origin != "env S1_SERVER_ADDR" {
+		t.Fatalf("unexpected origin; got %q", origin)
+	}
+	if origin := s1.Origin("limit"); origin != OriginCommandLine {
+		t.Fatalf("unexpected origin; got %q", origin)
+	}
+	if CommandLine.fs.Lookup("limit") != nil {
+		t.Fatalf("flags defined in Set mustn't leak into CommandLine")
+	}
+
+	var bb bytes.Buffer
+	s1.WriteFlags(&bb)
+	if s := bb.String(); s != "-env.prefix=\"s1_\" (from command line)\n-limit=\"1MiB\" (from command line)\n-server.addr=\":81\" (from env S1_SERVER_ADDR)\n" {
+		t.Fatalf("unexpected flags written:\n%s", s)
+	}
+	bb.Reset()
+	s2.WriteFlags(&bb)
+	if s := bb.String(); s != "-server.addr=\"secret\" (from env SERVER_ADDR)\n" {
+		t.Fatalf("unexpected flags written:\n%s", s)
+	}
+}
+
+func TestSetAddSource(t *testing.T) {
+	s := NewSet("test", flag.ContinueOnError)
+	a := s.NewString("a", "", "")
+	b := s.NewString("b", "", "")
+	src, err := NewFileSource(writeTestFile(t, "extra.yaml", "a: file\nb: file\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s.AddSource(src)
+	t.Setenv("A", "env")
+	if err := s.Parse(nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if *a != "env" || *b != "file" {
+		t.Fatalf("unexpected values; got %q, %q", *a, *b)
+	}
+}
+
+func TestParseFlagSetSharedState(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("setfor.test.value", "", "")
+	if setFor(fs) != ForFlagSet(fs) {
+		t.Fatalf("setFor must return the same Set for the same flag set")
+	}
+
+	// Origin must be safe to call concurrently with parsing the flag set.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = ForFlagSet(fs).Origin("setfor.test.value")
+		}
+	}()
+	t.Setenv("TEST_SETFOR_VALUE", "foo")
+	if err := ParseFlagSetE(fs, []string{"-setfor.test.value=secretref://env/TEST_SETFOR_VALUE"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	<-done
+	if v := fs.Lookup("setfor.test.value").Value.String(); v != "foo" {
+		t.Fatalf("unexpected value; got %q; want %q", v, "foo")
+	}
+	if !IsSecretFlag("setfor.test.value") {
+		t.Fatalf("the flag with resolved value must be secret")
+	}
+	if origin := ForFlagSet(fs).Origin("setfor.test.value"); origin != OriginCommandLine {
+		t.Fatalf("unexpected origin; got %q; want %q", origin, OriginCommandLine)
+	}
+	if origin := Origin("setfor.test.value"); origin != OriginDefault {
+		t.Fatalf("origins of other flag sets mustn't leak into CommandLine; got %q", origin)
+	}
+}
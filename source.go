@@ -0,0 +1,159 @@
+package flagx
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Source provides flag values from places other than the command line,
+// such as environment vars or config files.
+type Source interface {
+	// Lookup returns the raw value for the flag with the given name.
+	//
+	// false is returned if the source has no value for the flag.
+	Lookup(name string) (string, bool)
+
+	// Provenance returns human-readable description of where the value
+	// for the flag with the given name is defined, i.e. `env SERVER_ADDR` or `file config.yaml:12`.
+	Provenance(name string) string
+}
+
+// EnvSource returns Source, which reads flag values from environment vars for CommandLine.
+//
+// See Set.EnvSource for details.
+func EnvSource() Source {
+	return CommandLine.EnvSource()
+}
+
+// EnvSource returns Source, which reads flag values from environment vars.
+//
+// Env var names are derived from flag names by replacing dots with underscores,
+// converting to upper case and prepending the prefix from -env.prefix flag.
+// Empty env vars are ignored.
+//
+// Values for flags registered via EnableSecretFiles are also read from files
+// at paths in env vars with `_FILE` suffix.
+func (s *Set) EnvSource() Source {
+	return envSource{s: s}
+}
+
+type envSource struct {
+	s *Set
+}
+
+// Lookup implements Source interface
+func (es envSource) Lookup(name string) (string, bool) {
+	if v := os.Getenv(es.s.getEnvFlagName(name)); v != "" {
+		return v, true
+	}
+	if fileEnvName := es.s.getSecretFileEnvName(name); fileEnvName != "" {
+		if path := os.Getenv(fileEnvName); path != "" {
+			// The file is read by Set.resolveValue.
+			return "@" + path, true
+		}
+	}
+	return "", false
+}
+
+// Provenance implements Source interface
+func (es envSource) Provenance(name string) string {
+	envName := es.s.getEnvFlagName(name)
+	if os.Getenv(envName) == "" {
+		if fileEnvName := es.s.getSecretFileEnvName(name); fileEnvName != "" && os.Getenv(fileEnvName) != "" {
+			return "env " + fileEnvName
+		}
+	}
+	return "env " + envName
+}
+
+// FileSource is a Source reading flag values from YAML, TOML or JSON config file.
+//
+// Nested keys map onto dotted flag names, while sequences map onto comma-separated values for Array* flags.
+type FileSource struct {
+	path   string
+	values map[string]*fileValue
+}
+
+// NewFileSource reads the config file at path and returns FileSource for it.
+//
+// The file format is detected by the file extension: .toml files are parsed as TOML,
+// .json files are parsed as JSON and the rest of files are parsed as YAML.
+func NewFileSource(path string) (*FileSource, error) {
+	values, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSource{
+		path:   path,
+		values: values,
+	}, nil
+}
+
+// Path returns the path to the config file.
+func (s *FileSource) Path() string {
+	return s.path
+}
+
+// Lookup implements Source interface
+func (s *FileSource) Lookup(name string) (string, bool) {
+	fv, ok := s.values[name]
+	if !ok {
+		return "", false
+	}
+	return fv.value, true
+}
+
+// Provenance implements Source interface
+func (s *FileSource) Provenance(name string) string {
+	fv, ok := s.values[name]
+	if !ok {
+		return "file " + s.path
+	}
+	return "file " + s.location(fv)
+}
+
+// Check verifies that all the values in the config file correspond to flags registered in fs.
+//
+// The error points to the config file line with the first unknown flag.
+func (s *FileSource) Check(fs *flag.FlagSet) error {
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := s.values[names[i]], s.values[names[j]]
+		if a.line != b.line {
+			return a.line < b.line
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		fv := s.values[name]
+		f := fs.Lookup(name)
+		if f == nil {
+			if fv.isNull {
+				// Empty values for unknown flags are sections with all the keys commented out.
+				continue
+			}
+			return fmt.Errorf("%s: unknown flag %q", s.location(fv), name)
+		}
+		if fv.isArray && !isArrayValue(f.Value) {
+			return fmt.Errorf("%s: flag %q doesn't accept multiple values", s.location(fv), name)
+		}
+	}
+	return nil
+}
+
+func (s *FileSource) location(fv *fileValue) string {
+	if fv.line <= 0 {
+		return s.path
+	}
+	return fmt.Sprintf("%s:%d", s.path, fv.line)
+}
+
+// flagSetChecker is implemented by sources, which may verify their contents against the registered flags.
+type flagSetChecker interface {
+	Check(fs *flag.FlagSet) error
+}
@@ -1,7 +1,6 @@
 package flagx
 
 import (
-	"flag"
 	"fmt"
 	"os"
 	"strings"
@@ -9,10 +8,17 @@ import (
 
 // Usage prints s and optional description for all the flags if -h or -help flag is passed to the app.
 func Usage(s string) {
-	f := flag.CommandLine.Output()
-	fmt.Fprintf(f, "%s\n", s)
+	CommandLine.Usage(s)
+}
+
+// Usage prints desc and optional description for all the flags if -h or -help flag is passed to the app.
+//
+// The description is limited to flags matching the filter passed via `-help=filter`. See WriteHelp for details.
+func (s *Set) Usage(desc string) {
+	f := s.fs.Output()
+	fmt.Fprintf(f, "%s\n", desc)
 	if hasHelpFlag(os.Args[1:]) {
-		flag.PrintDefaults()
+		s.WriteHelp(f, getHelpFilter(os.Args[1:]))
 	} else {
 		fmt.Fprintf(f, `Run "%s -help" in order to see the description for all the available flags`+"\n", os.Args[0])
 	}
@@ -32,5 +38,6 @@ func isHelpArg(arg string) bool {
 		return false
 	}
 	arg = strings.TrimPrefix(arg[1:], "-")
+	arg, _, _ = strings.Cut(arg, "=")
 	return arg == "h" || arg == "help"
 }